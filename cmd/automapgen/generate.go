@@ -0,0 +1,577 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// field is a field with an automap tag, possibly promoted from embedded structs.
+type field struct {
+	tag string
+	// path holds the embedded structs leading to the field, and the field itself last.
+	path []hop
+	expr ast.Expr
+}
+
+// hop is a single step of a field path.
+type hop struct {
+	name string
+	// ptr is set when the step is an embedded pointer, which may be nil.
+	ptr bool
+}
+
+// access returns the selector of the field, relative to the struct, e.g. 'Base.Name'.
+func (f field) access() string {
+
+	names := make([]string, len(f.path))
+	for i, h := range f.path {
+		names[i] = h.name
+	}
+
+	return strings.Join(names, ".")
+}
+
+type pkg struct {
+	name    string
+	structs map[string]*ast.StructType
+	// imports maps the names the files refer to imports by to their specs, such as 'time' to '"time"'.
+	// Names bound to different paths in different files map to an empty spec.
+	imports map[string]string
+	// used holds the import names referred to by the generated code.
+	used map[string]bool
+}
+
+// loadPackage parses the non-test Go files of the directory, skipping the previously generated output.
+func loadPackage(dir, output string) (*pkg, error) {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &pkg{structs: map[string]*ast.StructType{}, imports: map[string]string{}}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == output {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if p.name == "" {
+			p.name = file.Name.Name
+		}
+
+		for _, spec := range file.Imports {
+			name, ok := importName(spec)
+			if !ok {
+				continue
+			}
+			s := spec.Path.Value
+			if spec.Name != nil {
+				s = spec.Name.Name + " " + s
+			}
+			if existing, found := p.imports[name]; found && existing != s {
+				s = ""
+			}
+			p.imports[name] = s
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ts.TypeParams != nil {
+					continue
+				}
+				p.structs[ts.Name.Name] = st
+			}
+		}
+	}
+
+	if p.name == "" {
+		return nil, fmt.Errorf("(no Go files found in '%s')", dir)
+	}
+
+	return p, nil
+}
+
+// importName returns the name the file refers to the import by: its explicit name or, as the Go tool would
+// without loading the package, the last element of its path, ignoring a major version suffix such as '/v2' or '.v3'
+// and a 'go-' prefix. Blank and dot imports are reported as not ok.
+func importName(spec *ast.ImportSpec) (string, bool) {
+
+	if spec.Name != nil {
+		return spec.Name.Name, spec.Name.Name != "_" && spec.Name.Name != "."
+	}
+
+	path, err := strconv.Unquote(spec.Path.Value)
+	if err != nil {
+		return "", false
+	}
+	elements := strings.Split(path, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && isMajorVersion(name) {
+		name = elements[len(elements)-2]
+	}
+	if i := strings.LastIndex(name, "."); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+
+	return name, name != ""
+}
+
+func isMajorVersion(s string) bool {
+
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	_, err := strconv.Atoi(s[1:])
+
+	return err == nil
+}
+
+// fields returns the fields of the struct by automap tag, following the rules of reflection.TaggedFields.
+//
+// Fields of embedded structs without a tag of their own are promoted. A field at a shallower depth hides fields
+// with the same tag at deeper depths, and fields with the same tag at the same depth are all left out.
+func (p *pkg) fields(name string) (map[string]field, error) {
+
+	var candidates []field
+	err := p.collect(name, nil, map[string]bool{}, &candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]field{}
+	ambiguous := map[string]bool{}
+	for _, c := range candidates {
+		existing, found := fields[c.tag]
+		switch {
+		case !found || len(c.path) < len(existing.path):
+			fields[c.tag] = c
+			ambiguous[c.tag] = false
+		case len(c.path) == len(existing.path):
+			ambiguous[c.tag] = true
+		}
+	}
+	for tag := range ambiguous {
+		if ambiguous[tag] {
+			delete(fields, tag)
+		}
+	}
+
+	return fields, nil
+}
+
+func (p *pkg) collect(name string, prefix []hop, visited map[string]bool, out *[]field) error {
+
+	// Guards against embedding cycles, such as 'type A struct { *A }'.
+	if visited[name] {
+		return nil
+	}
+	visited[name] = true
+	defer delete(visited, name)
+
+	for _, f := range p.structs[name].Fields.List {
+
+		tag := automapTag(f.Tag)
+		if tag == "-" {
+			continue
+		}
+
+		if len(f.Names) > 0 {
+			if tag == "" {
+				continue
+			}
+			for _, n := range f.Names {
+				*out = append(*out, field{tag: tag, path: extend(prefix, hop{name: n.Name}), expr: f.Type})
+			}
+			continue
+		}
+
+		// An embedded field is named after its type.
+		base, ptr := peel(f.Type)
+		typeName := exprString(base)
+		if sel, ok := base.(*ast.SelectorExpr); ok {
+			typeName = sel.Sel.Name
+		}
+		if tag != "" {
+			*out = append(*out, field{tag: tag, path: extend(prefix, hop{name: typeName}), expr: f.Type})
+			continue
+		}
+
+		ident, ok := base.(*ast.Ident)
+		if !ok || ptr > 1 || p.structs[ident.Name] == nil {
+			return fmt.Errorf(
+				"(%s: embedded field '%s' must be a struct declared in the package, or have an automap tag)",
+				name, exprString(f.Type),
+			)
+		}
+		err := p.collect(ident.Name, extend(prefix, hop{name: typeName, ptr: ptr == 1}), visited, out)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func automapTag(lit *ast.BasicLit) string {
+
+	if lit == nil {
+		return ""
+	}
+	raw, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return ""
+	}
+	full := reflect.StructTag(raw).Get("automap")
+
+	return strings.TrimSpace(strings.Split(full, ",")[0])
+}
+
+func extend(path []hop, h hop) []hop {
+	return append(append([]hop{}, path...), h)
+}
+
+// generate returns the formatted source of the mapping functions for the pairs, and for every nested pair they need.
+func generate(p *pkg, pairs []pair) ([]byte, error) {
+
+	var body bytes.Buffer
+	p.used = map[string]bool{}
+	done := map[pair]bool{}
+	queue := append([]pair{}, pairs...)
+
+	for len(queue) > 0 {
+
+		current := queue[0]
+		queue = queue[1:]
+		if done[current] {
+			continue
+		}
+		done[current] = true
+
+		nested, err := p.writeFunc(&body, current)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, nested...)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by automapgen; DO NOT EDIT.\n\n")
+	out.WriteString("package " + p.name + "\n")
+	if len(p.used) > 0 {
+		names := make([]string, 0, len(p.used))
+		for name := range p.used {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		out.WriteString("\nimport (\n")
+		for _, name := range names {
+			spec, found := p.imports[name]
+			if !found || spec == "" {
+				return nil, fmt.Errorf("(cannot resolve the import of '%s' - import it under that name in a single way)", name)
+			}
+			out.WriteString(spec + "\n")
+		}
+		out.WriteString(")\n")
+	}
+	out.Write(body.Bytes())
+
+	formatted, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("(failed to format generated code: %w)", err)
+	}
+
+	return formatted, nil
+}
+
+func funcName(pr pair) string {
+	return "Map" + pr.source + "To" + pr.target
+}
+
+// writeFunc writes the mapping function of a single pair, and returns the nested pairs it calls.
+func (p *pkg) writeFunc(w *bytes.Buffer, pr pair) ([]pair, error) {
+
+	if _, found := p.structs[pr.source]; !found {
+		return nil, fmt.Errorf("(source must be a struct declared in the package, got: '%s')", pr.source)
+	}
+	if _, found := p.structs[pr.target]; !found {
+		return nil, fmt.Errorf("(target must be a struct declared in the package, got: '%s')", pr.target)
+	}
+	sourceFields, err := p.fields(pr.source)
+	if err != nil {
+		return nil, err
+	}
+	targetFields, err := p.fields(pr.target)
+	if err != nil {
+		return nil, err
+	}
+
+	var nested []pair
+	fmt.Fprintf(w, "\n// %s maps %s to %s by their automap tags.\n", funcName(pr), pr.source, pr.target)
+	fmt.Fprintf(w, "func %s(source %s) (target %s) {\n\n", funcName(pr), pr.source, pr.target)
+
+	// Ordered by tag, for stable output.
+	tags := make([]string, 0, len(sourceFields))
+	for tag := range sourceFields {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+
+		sf := sourceFields[tag]
+		tf, found := targetFields[tag]
+		if !found || !allocatable(tf) {
+			continue
+		}
+
+		// Fields promoted through a nil embedded pointer are nil as well, and skipped.
+		var guards []string
+		for i, h := range sf.path[:len(sf.path)-1] {
+			if h.ptr {
+				guards = append(guards, "source."+field{path: sf.path[:i+1]}.access()+" != nil")
+			}
+		}
+		if len(guards) > 0 {
+			fmt.Fprintf(w, "if %s {\n", strings.Join(guards, " && "))
+		}
+		for i, h := range tf.path[:len(tf.path)-1] {
+			if h.ptr {
+				t := "target." + field{path: tf.path[:i+1]}.access()
+				fmt.Fprintf(w, "if %s == nil {\n%s = new(%s)\n}\n", t, t, h.name)
+			}
+		}
+
+		n, err := p.assign(w, "source."+sf.access(), "target."+tf.access(), sf.expr, tf.expr, 0)
+		if err != nil {
+			return nil, fmt.Errorf("(%s.%s: %s)", pr.source, sf.access(), err)
+		}
+		nested = append(nested, n...)
+
+		if len(guards) > 0 {
+			w.WriteString("}\n")
+		}
+	}
+
+	w.WriteString("\nreturn target\n}\n")
+
+	return nested, nil
+}
+
+// allocatable reports whether the embedded pointers leading to the field can be allocated by reflection.AutoMap,
+// which skips fields behind unexported ones.
+func allocatable(f field) bool {
+
+	for _, h := range f.path[:len(f.path)-1] {
+		if h.ptr && !ast.IsExported(h.name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// assign writes the statements mapping 'src' of type 'sx' onto 'dst' of type 'tx', and returns the nested pairs they call.
+//
+// As with reflection.AutoMap, pointers are peeled, nil sources are skipped, and cognate structs and collections of them
+// are mapped recursively. Local variables are suffixed by depth, so that nested collections do not shadow each other.
+func (p *pkg) assign(w *bytes.Buffer, src, dst string, sx, tx ast.Expr, depth int) ([]pair, error) {
+
+	sBase, sPtr := peel(sx)
+	tBase, tPtr := peel(tx)
+	if sPtr > 1 || tPtr > 1 {
+		return nil, fmt.Errorf("only a single level of pointers is supported")
+	}
+
+	sType := exprString(sBase)
+	tType := exprString(tBase)
+	_, sStruct := p.structs[sType]
+	_, tStruct := p.structs[tType]
+	if sType != tType && !(sStruct && tStruct) {
+		return p.assignCollection(w, src, dst, sBase, tBase, sPtr, tPtr, depth)
+	}
+
+	// convert is applied to the peeled source value.
+	var nested []pair
+	convert := func(v string) string { return v }
+	if sType != tType {
+		np := pair{source: sType, target: tType}
+		nested = append(nested, np)
+		convert = func(v string) string { return funcName(np) + "(" + v + ")" }
+	}
+
+	v := local("v", depth)
+	switch {
+	case sPtr == 0 && tPtr == 0:
+		fmt.Fprintf(w, "%s = %s\n", dst, convert(src))
+	case sPtr == 1 && tPtr == 0:
+		fmt.Fprintf(w, "if %s != nil {\n%s = %s\n}\n", src, dst, convert("*"+src))
+	case sPtr == 0 && tPtr == 1:
+		fmt.Fprintf(w, "{\n%s := %s\n%s = &%s\n}\n", v, convert(src), dst, v)
+	default:
+		fmt.Fprintf(w, "if %s != nil {\n%s := %s\n%s = &%s\n}\n", src, v, convert("*"+src), dst, v)
+	}
+
+	return nested, nil
+}
+
+// assignCollection maps slices, arrays and maps element by element. Nil elements are left as zero values.
+func (p *pkg) assignCollection(w *bytes.Buffer, src, dst string, sBase, tBase ast.Expr, sPtr, tPtr, depth int) ([]pair, error) {
+
+	mismatch := fmt.Errorf(
+		"source and target type mismatch - source: '%s', target: '%s' (registered converters are not supported)",
+		exprString(sBase), exprString(tBase),
+	)
+
+	var guards []string
+	if sPtr == 1 {
+		guards = append(guards, src+" != nil")
+		src = "(*" + src + ")"
+	}
+
+	v, i, k, e, x := local("v", depth), local("i", depth), local("k", depth), local("e", depth), local("x", depth)
+	tType := p.typeName(tBase)
+
+	var sElem, tElem ast.Expr
+	var head strings.Builder
+	isMap := false
+	switch s := sBase.(type) {
+	case *ast.ArrayType:
+		t, ok := tBase.(*ast.ArrayType)
+		if !ok || (s.Len == nil) != (t.Len == nil) {
+			return nil, mismatch
+		}
+		if s.Len == nil {
+			guards = append(guards, src+" != nil")
+			fmt.Fprintf(&head, "%s := make(%s, len(%s))\n", v, tType, src)
+		} else {
+			if exprString(s.Len) != exprString(t.Len) {
+				return nil, fmt.Errorf(
+					"source and target array length mismatch - source: '%s', target: '%s'",
+					exprString(s.Len), exprString(t.Len),
+				)
+			}
+			fmt.Fprintf(&head, "var %s %s\n", v, tType)
+		}
+		fmt.Fprintf(&head, "for %s, %s := range %s {\n", i, e, src)
+		sElem, tElem = s.Elt, t.Elt
+
+	case *ast.MapType:
+		t, ok := tBase.(*ast.MapType)
+		if !ok {
+			return nil, mismatch
+		}
+		if exprString(s.Key) != exprString(t.Key) {
+			return nil, fmt.Errorf(
+				"source and target map key mismatch - source: '%s', target: '%s'",
+				exprString(s.Key), exprString(t.Key),
+			)
+		}
+		guards = append(guards, src+" != nil")
+		fmt.Fprintf(&head, "%s := make(%s, len(%s))\n", v, tType, src)
+		fmt.Fprintf(&head, "for %s, %s := range %s {\n", k, e, src)
+		fmt.Fprintf(&head, "var %s %s\n", x, p.typeName(t.Value))
+		sElem, tElem = s.Value, t.Value
+		isMap = true
+
+	default:
+		return nil, mismatch
+	}
+
+	if len(guards) > 0 {
+		fmt.Fprintf(w, "if %s {\n", strings.Join(guards, " && "))
+	} else {
+		w.WriteString("{\n")
+	}
+	w.WriteString(head.String())
+
+	element := v + "[" + i + "]"
+	if isMap {
+		element = x
+	}
+	nested, err := p.assign(w, e, element, sElem, tElem, depth+1)
+	if err != nil {
+		return nil, err
+	}
+	if isMap {
+		fmt.Fprintf(w, "%s[%s] = %s\n", v, k, x)
+	}
+	w.WriteString("}\n")
+
+	if tPtr == 1 {
+		fmt.Fprintf(w, "%s = &%s\n}\n", dst, v)
+	} else {
+		fmt.Fprintf(w, "%s = %s\n}\n", dst, v)
+	}
+
+	return nested, nil
+}
+
+func local(name string, depth int) string {
+
+	if depth == 0 {
+		return name
+	}
+
+	return name + strconv.Itoa(depth)
+}
+
+func peel(expr ast.Expr) (ast.Expr, int) {
+
+	depth := 0
+	for {
+		star, ok := expr.(*ast.StarExpr)
+		if !ok {
+			return expr, depth
+		}
+		expr = star.X
+		depth++
+	}
+}
+
+// typeName returns the source of the type, to be written in the generated code, and records the imports it refers to.
+func (p *pkg) typeName(expr ast.Expr) string {
+
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok {
+			p.used[id.Name] = true
+		}
+		return false
+	})
+
+	return exprString(expr)
+}
+
+func exprString(expr ast.Expr) string {
+
+	var b bytes.Buffer
+	_ = format.Node(&b, token.NewFileSet(), expr)
+
+	return b.String()
+}
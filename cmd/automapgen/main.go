@@ -0,0 +1,103 @@
+// Command automapgen generates plain Go mapping functions with the semantics of reflection.AutoMap.
+//
+// It reads the 'automap' tags of pairs of struct types declared in a package, and for each pair
+// Source:Target emits a function 'MapSourceToTarget(source Source) Target'. As with AutoMap, pointers
+// are peeled, nil sources are skipped, fields of embedded structs are promoted, and nested structs of different
+// types are mapped recursively, also as the elements of slices, arrays and maps. Types of other packages named in
+// the generated code are imported as the package's files import them.
+//
+// Converters registered with reflection.RegisterConverter only exist at run time, so fields that need one are
+// reported as a type mismatch. So are untagged embedded fields whose type is not a struct declared in the package.
+//
+// Typical use, from within the package declaring the types:
+//
+//	//go:generate go run github.com/pergamenum/go-consensus-standards/cmd/automapgen -pairs UserModel:UserEntity,UserEntity:UserModel
+//
+// With -verify, nothing is written, and the command fails if the output file is missing or stale.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+
+	dir := flag.String("dir", ".", "directory of the package declaring the types")
+	pairs := flag.String("pairs", "", "comma-separated Source:Target type pairs")
+	output := flag.String("output", "automap_gen.go", "name of the generated file, relative to -dir")
+	verify := flag.Bool("verify", false, "fail if the generated file is stale, instead of writing it")
+	flag.Parse()
+
+	err := run(*dir, *pairs, *output, *verify)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "automapgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, pairs, output string, verify bool) error {
+
+	ps, err := parsePairs(pairs)
+	if err != nil {
+		return err
+	}
+
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return err
+	}
+
+	generated, err := generate(pkg, ps)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, output)
+	if !verify {
+		return os.WriteFile(path, generated, 0o644)
+	}
+
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("(verify: %w)", err)
+	}
+	if !bytes.Equal(existing, generated) {
+		return fmt.Errorf("(verify: '%s' is stale - rerun go generate)", path)
+	}
+
+	return nil
+}
+
+type pair struct {
+	source string
+	target string
+}
+
+func parsePairs(input string) ([]pair, error) {
+
+	var ps []pair
+	for _, p := range strings.Split(input, ",") {
+
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+
+		split := strings.Split(p, ":")
+		if len(split) != 2 || split[0] == "" || split[1] == "" {
+			return nil, fmt.Errorf("(invalid pair '%s' - pairs must be Source:Target)", p)
+		}
+		ps = append(ps, pair{source: split[0], target: split[1]})
+	}
+
+	if len(ps) == 0 {
+		return nil, fmt.Errorf("(no pairs given - use -pairs Source:Target)")
+	}
+
+	return ps, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pergamenum/go-consensus-standards/cmd/automapgen/testdata/models"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+func Test_Generate_Golden(t *testing.T) {
+
+	p, err := loadPackage("testdata/models", "automap_gen.go")
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	generated, err := generate(p, []pair{
+		{source: "User", target: "UserEntity"},
+		{source: "Order", target: "OrderEntity"},
+		{source: "Schedule", target: "ScheduleEntity"},
+	})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	golden, err := os.ReadFile("testdata/models/automap_gen.go")
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if !bytes.Equal(generated, golden) {
+		fmt.Printf("generated code differs from the golden file:\n%s\n", generated)
+		t.Fail()
+	}
+}
+
+func Test_Verify(t *testing.T) {
+
+	dir := t.TempDir()
+	source, _ := os.ReadFile("testdata/models/models.go")
+	_ = os.WriteFile(filepath.Join(dir, "models.go"), source, 0o644)
+
+	err := run(dir, "User:UserEntity", "automap_gen.go", true)
+	if err == nil {
+		fmt.Println("verify should fail when nothing has been generated")
+		t.Fail()
+	}
+
+	err = run(dir, "User:UserEntity", "automap_gen.go", false)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	err = run(dir, "User:UserEntity", "automap_gen.go", true)
+	if err != nil {
+		fmt.Println("verify should pass right after generating, got:", err)
+		t.Fail()
+	}
+
+	stale := append(source, []byte("\ntype Extra struct{}\n\ntype Other struct {\n\tExtra string `automap:\"extra\"`\n}\n")...)
+	_ = os.WriteFile(filepath.Join(dir, "models.go"), stale, 0o644)
+	err = run(dir, "User:UserEntity,Other:UserEntity", "automap_gen.go", true)
+	if err == nil {
+		fmt.Println("verify should fail when the pairs have changed")
+		t.Fail()
+	}
+}
+
+func Test_Generate_Errors(t *testing.T) {
+
+	p, err := loadPackage("testdata/models", "automap_gen.go")
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	bad := [][]pair{
+		{{source: "Missing", target: "UserEntity"}},
+		{{source: "User", target: "Missing"}},
+		{{source: "User", target: "Mismatch"}},
+		{{source: "Stamped", target: "UserEntity"}},
+	}
+
+	for _, ps := range bad {
+		if _, err := generate(p, ps); err == nil {
+			fmt.Println("expected an error for", ps)
+			t.Fail()
+		}
+	}
+
+	p.imports["clock"] = ""
+	if _, err := generate(p, []pair{{source: "Schedule", target: "ScheduleEntity"}}); err == nil {
+		fmt.Println("expected an error for an import bound to different paths")
+		t.Fail()
+	}
+
+	for _, input := range []string{"", "User", "User:", ":User,A:B"} {
+		if _, err := parsePairs(input); err == nil {
+			fmt.Printf("'%s' should not parse as pairs\n", input)
+			t.Fail()
+		}
+	}
+}
+
+// Test_Generate_Equivalence runs the golden file, kept current by Test_Generate_Golden, against reflection.AutoMap.
+// Being compiled into the test, the golden file also shows that the generated code, imports included, compiles.
+func Test_Generate_Equivalence(t *testing.T) {
+
+	age := 30
+	qty := 2
+	street := models.Address{Street: "Main"}
+	friend := models.User{Name: "Bob", Previous: &street}
+	users := []models.User{
+		{},
+		{Name: "Alice", Age: &age, Created: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Address: street, Friend: &friend},
+	}
+	for _, u := range users {
+		generated := models.MapUserToUserEntity(u)
+		mapped, err := reflection.AutoMap[models.UserEntity](u)
+		if err != nil || !reflect.DeepEqual(generated, mapped) {
+			fmt.Printf("%+v: generated %+v, AutoMap %+v (%v)\n", u, generated, mapped, err)
+			t.Fail()
+		}
+	}
+
+	items := []models.Item{{SKU: "a", Qty: &qty}, {SKU: "b"}}
+	orders := []models.Order{
+		{},
+		{Items: []models.Item{}, ByID: map[string]*models.Item{}, Backup: &[]models.Item{}},
+		{
+			Base:   models.Base{ID: "1"},
+			Audit:  &models.Audit{CreatedBy: "x"},
+			Items:  items,
+			ByID:   map[string]*models.Item{"a": &items[0], "nil": nil},
+			Pair:   [2]models.Item{items[1], items[0]},
+			Grid:   [][]models.Item{items, nil},
+			Backup: &items,
+			Notes:  []string{"n"},
+		},
+	}
+	for _, o := range orders {
+		generated := models.MapOrderToOrderEntity(o)
+		mapped, err := reflection.AutoMap[models.OrderEntity](o)
+		if err != nil || !reflect.DeepEqual(generated, mapped) {
+			fmt.Printf("%+v: generated %+v, AutoMap %+v (%v)\n", o, generated, mapped, err)
+			t.Fail()
+		}
+	}
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	hour := time.Hour
+	schedules := []models.Schedule{
+		{},
+		{
+			Times:  []*time.Time{&now, nil},
+			Slots:  map[string]*time.Duration{"a": &hour, "nil": nil},
+			Breaks: [2]time.Duration{time.Minute, hour},
+			Zones:  map[string][]*time.Location{"utc": {time.UTC, nil}, "none": nil},
+		},
+	}
+	for _, sc := range schedules {
+		generated := models.MapScheduleToScheduleEntity(sc)
+		mapped, err := reflection.AutoMap[models.ScheduleEntity](sc)
+		if err != nil || !reflect.DeepEqual(generated, mapped) {
+			fmt.Printf("%+v: generated %+v, AutoMap %+v (%v)\n", sc, generated, mapped, err)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Import_Name(t *testing.T) {
+
+	tests := map[string]string{
+		`"time"`:                        "time",
+		`"net/url"`:                     "url",
+		`"github.com/jackc/pgx/v5"`:     "pgx",
+		`"gopkg.in/yaml.v3"`:            "yaml",
+		`"github.com/mattn/go-sqlite3"`: "sqlite3",
+		`clock "time"`:                  "clock",
+	}
+
+	for source, expected := range tests {
+		file, err := parser.ParseFile(token.NewFileSet(), "", "package p\nimport "+source, parser.ImportsOnly)
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+		if name, ok := importName(file.Imports[0]); !ok || name != expected {
+			fmt.Printf("%s: expected '%s', got '%s'\n", source, expected, name)
+			t.Fail()
+		}
+	}
+}
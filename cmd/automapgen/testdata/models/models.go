@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+type Address struct {
+	Street string `automap:"street"`
+}
+
+type AddressEntity struct {
+	Street *string `automap:"street"`
+}
+
+type User struct {
+	Name     string    `automap:"name"`
+	Age      *int      `automap:"age"`
+	Created  time.Time `automap:"created"`
+	Address  Address   `automap:"address"`
+	Previous *Address  `automap:"previous"`
+	Friend   *User     `automap:"friend"`
+	Internal string
+	Ignored  string `automap:"-"`
+}
+
+type UserEntity struct {
+	Name     *string        `automap:"name"`
+	Age      int            `automap:"age"`
+	Created  time.Time      `automap:"created"`
+	Address  *AddressEntity `automap:"address"`
+	Previous AddressEntity  `automap:"previous"`
+	Friend   *UserEntity    `automap:"friend"`
+	Extra    string         `automap:"extra"`
+}
+
+type Mismatch struct {
+	Name int `automap:"name"`
+}
+
+type Base struct {
+	ID string `automap:"id"`
+}
+
+type Audit struct {
+	CreatedBy string `automap:"created_by"`
+}
+
+type Item struct {
+	SKU string `automap:"sku"`
+	Qty *int   `automap:"qty"`
+}
+
+type ItemEntity struct {
+	SKU string `automap:"sku"`
+	Qty int    `automap:"qty"`
+}
+
+type Order struct {
+	Base
+	*Audit
+	Items  []Item           `automap:"items"`
+	ByID   map[string]*Item `automap:"by_id"`
+	Pair   [2]Item          `automap:"pair"`
+	Grid   [][]Item         `automap:"grid"`
+	Backup *[]Item          `automap:"backup"`
+	Notes  []string         `automap:"notes"`
+}
+
+type OrderEntity struct {
+	*Base
+	Audit
+	Items  []*ItemEntity         `automap:"items"`
+	ByID   map[string]ItemEntity `automap:"by_id"`
+	Pair   [2]ItemEntity         `automap:"pair"`
+	Grid   [][]ItemEntity        `automap:"grid"`
+	Backup []ItemEntity          `automap:"backup"`
+	Notes  []string              `automap:"notes"`
+}
+
+type Stamped struct {
+	time.Time
+}
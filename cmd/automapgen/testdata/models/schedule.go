@@ -0,0 +1,19 @@
+package models
+
+import (
+	clock "time"
+)
+
+type Schedule struct {
+	Times  []*clock.Time                `automap:"times"`
+	Slots  map[string]*clock.Duration   `automap:"slots"`
+	Breaks [2]clock.Duration            `automap:"breaks"`
+	Zones  map[string][]*clock.Location `automap:"zones"`
+}
+
+type ScheduleEntity struct {
+	Times  []clock.Time                `automap:"times"`
+	Slots  map[string]clock.Duration   `automap:"slots"`
+	Breaks [2]clock.Duration           `automap:"breaks"`
+	Zones  map[string][]clock.Location `automap:"zones"`
+}
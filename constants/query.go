@@ -5,6 +5,12 @@ const QueryTimeFormat = "2006-01-02_15:04"
 // QueryTimeHint is the human-readable explanation of QueryTimeFormat. Intended for use with error reporting.
 const QueryTimeHint = "YYYY-MM-DD_hh:mm"
 
+// QueryRelativeTimeHint is the human-readable explanation of relative time values. Intended for use with error reporting.
+const QueryRelativeTimeHint = "'now', 'today', 'startOfWeek', 'startOfMonth' or 'startOfYear', with an optional offset such as 'now-24h' or 'today-7d'"
+
+// QueryRefPrefix marks a query value as a reference to another field, e.g. 'updated,GT,@created'.
+const QueryRefPrefix = "@"
+
 var ValidRelationalOperators = map[string]bool{
 	"EQ": true,
 	"NE": true,
@@ -13,3 +19,80 @@ var ValidRelationalOperators = map[string]bool{
 	"LE": true,
 	"GE": true,
 }
+
+// ValidEqualityOperators are the relational operators that apply to fields of every kind.
+var ValidEqualityOperators = map[string]bool{
+	"EQ": true,
+	"NE": true,
+}
+
+// ValidOrderingOperators are the relational operators that, by default, only apply to numbers and times.
+var ValidOrderingOperators = map[string]bool{
+	"LT": true,
+	"GT": true,
+	"LE": true,
+	"GE": true,
+}
+
+// SearchOptionKeys are the URL parameters reserved for types.SearchOptions, alongside the 'q' parameter of queries.
+var SearchOptionKeys = map[string]bool{
+	"sort":   true,
+	"limit":  true,
+	"offset": true,
+	"cursor": true,
+	"filter": true,
+}
+
+// MaxSearchLimit is the default upper bound on the number of items in a single page of search results.
+const MaxSearchLimit = 100
+
+// ValidSetOperators test membership of a list value. IN and NIN (not in) take one or more elements.
+var ValidSetOperators = map[string]bool{
+	"IN":  true,
+	"NIN": true,
+}
+
+// ValidStringOperators match substrings of string fields. The I-prefixed variants ignore case.
+var ValidStringOperators = map[string]bool{
+	"CONTAINS":  true,
+	"PREFIX":    true,
+	"SUFFIX":    true,
+	"ICONTAINS": true,
+	"IPREFIX":   true,
+	"ISUFFIX":   true,
+}
+
+// ValidRangeOperators take a list value of exactly two elements: the inclusive lower and upper bound.
+var ValidRangeOperators = map[string]bool{
+	"BETWEEN": true,
+}
+
+// ValidNullOperators test whether a field is nil. Their value is ignored.
+var ValidNullOperators = map[string]bool{
+	"ISNULL":  true,
+	"NOTNULL": true,
+}
+
+// ValidOperators holds every supported operator.
+var ValidOperators = union(
+	ValidRelationalOperators,
+	ValidSetOperators,
+	ValidStringOperators,
+	ValidRangeOperators,
+	ValidNullOperators,
+)
+
+// QueryListSeparator separates the elements of a list value in its string form, e.g. 'open|pending'.
+const QueryListSeparator = "|"
+
+func union(ms ...map[string]bool) map[string]bool {
+
+	u := map[string]bool{}
+	for _, m := range ms {
+		for k, v := range m {
+			u[k] = v
+		}
+	}
+
+	return u
+}
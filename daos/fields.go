@@ -0,0 +1,106 @@
+package daos
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+// DefaultTagKeys are the struct tag keys consulted, in order, when resolving a query or update key to a field.
+var DefaultTagKeys = []string{"automap", "json"}
+
+// pathKey identifies a resolved path in the paths cache.
+type pathKey struct {
+	t       reflect.Type
+	tagKeys string
+	key     string
+}
+
+// paths caches the path of every key resolved by pathByTag, as the fields and tags of a type never change.
+//
+//	Unknown keys are not cached, since they come from requests and would grow the cache without bound.
+var paths sync.Map
+
+// pathByTag returns the path to the field of struct type 't' whose tag, under any of the given tag keys, equals 'key'.
+//
+//	Keys may address nested struct fields with dotted tags, and fields promoted from embedded structs are included.
+//	Unexported fields are left out, as they can neither be read nor set through reflection.
+func pathByTag(t reflect.Type, tagKeys []string, key string) (reflection.TaggedPath, bool) {
+
+	pk := pathKey{t: t, tagKeys: strings.Join(tagKeys, " "), key: key}
+	if cached, found := paths.Load(pk); found {
+		return cached.(reflection.TaggedPath), true
+	}
+
+	for _, tk := range tagKeys {
+		if p, found := reflection.ResolvePath(t, tk, key); found && exported(p) {
+			paths.Store(pk, p)
+			return p, true
+		}
+	}
+
+	return reflection.TaggedPath{}, false
+}
+
+// fieldByTag returns the field of the struct 'v' whose tag, under any of the given tag keys, equals 'key'.
+//
+//	A field behind a nil pointer, embedded or nested, is returned as a nil pointer to its type.
+func fieldByTag(v reflect.Value, tagKeys []string, key string) (reflect.Value, bool) {
+
+	p, found := pathByTag(v.Type(), tagKeys, key)
+	if !found {
+		return reflect.Value{}, false
+	}
+
+	field, ok := p.Value(v)
+	if !ok {
+		return reflect.Zero(reflect.PointerTo(p.Field().Type)), true
+	}
+
+	return field, true
+}
+
+// settableFieldByTag is like fieldByTag, but prepares the field to be set without affecting other copies of the entity.
+//
+// Pointers along the way, embedded or nested, are allocated when nil and copied otherwise.
+//
+//	Reports false when the field is unknown, or cannot be reached as it lies behind an unexported pointer.
+func settableFieldByTag(v reflect.Value, tagKeys []string, key string) (reflect.Value, bool) {
+
+	p, found := pathByTag(v.Type(), tagKeys, key)
+	if !found {
+		return reflect.Value{}, false
+	}
+
+	for _, f := range p.Fields {
+		for _, x := range f.Index {
+			for v.Kind() == reflect.Pointer {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				copied := reflect.New(v.Type().Elem())
+				if !v.IsNil() {
+					copied.Elem().Set(v.Elem())
+				}
+				v.Set(copied)
+				v = v.Elem()
+			}
+			v = v.Field(x)
+		}
+	}
+
+	return v, true
+}
+
+func exported(p reflection.TaggedPath) bool {
+
+	for _, f := range p.Fields {
+		if !f.Field.IsExported() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,385 @@
+package daos
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+	t "github.com/pergamenum/go-consensus-standards/types"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// matchAll reports whether the struct 'v' satisfies every query.
+func matchAll(v reflect.Value, tagKeys []string, queries []t.Query) (bool, error) {
+
+	for _, q := range queries {
+		ok, err := match(v, tagKeys, q)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchExpression reports whether the struct 'v' satisfies the expression 'x'.
+func matchExpression(v reflect.Value, tagKeys []string, x t.Expression) (bool, error) {
+
+	switch x.Operator {
+
+	case "":
+		return match(v, tagKeys, x.Query)
+
+	case "AND", "OR":
+		if x.Operator == "OR" && len(x.Children) == 0 {
+			return false, e.Wrap("(expression 'OR' has no children)", e.ErrBadRequest)
+		}
+		// AND stops at the first false child, OR at the first true child. An AND without children matches everything.
+		stop := x.Operator == "OR"
+		for _, child := range x.Children {
+			ok, err := matchExpression(v, tagKeys, child)
+			if err != nil {
+				return false, err
+			}
+			if ok == stop {
+				return stop, nil
+			}
+		}
+		return !stop, nil
+
+	case "NOT":
+		if len(x.Children) != 1 {
+			return false, e.Wrap("(expression 'NOT' must have exactly one child)", e.ErrBadRequest)
+		}
+		ok, err := matchExpression(v, tagKeys, x.Children[0])
+		return !ok, err
+
+	default:
+		cause := fmt.Sprintf("(unsupported expression operator '%s')", x.Operator)
+		return false, e.Wrap(cause, e.ErrBadRequest)
+	}
+}
+
+// match reports whether the struct 'v' satisfies the query 'q'.
+//
+//	Nil fields never match, except for the null operators, mirroring how SQL treats NULL.
+func match(v reflect.Value, tagKeys []string, q t.Query) (bool, error) {
+
+	field, found := fieldByTag(v, tagKeys, q.Key)
+	if !found {
+		cause := fmt.Sprintf("(unknown query key '%s')", q.Key)
+		return false, e.Wrap(cause, e.ErrBadRequest)
+	}
+
+	for field.Kind() == reflect.Pointer && !field.IsNil() {
+		field = field.Elem()
+	}
+	isNil := reflection.Nillable(field) && field.IsNil()
+
+	switch q.Operator {
+	case "ISNULL":
+		return isNil, nil
+	case "NOTNULL":
+		return !isNil, nil
+	}
+
+	if isNil {
+		return false, nil
+	}
+
+	q, found, err := resolveRefs(v, tagKeys, q)
+	if err != nil || !found {
+		return false, err
+	}
+
+	var ok bool
+	if field.Kind() == reflect.Slice || field.Kind() == reflect.Array {
+		ok, err = matchElements(field, q)
+	} else {
+		ok, err = matchValue(field, q)
+	}
+	if err != nil {
+		cause := fmt.Sprintf("(query key '%s')", q.Key)
+		return false, e.Wrap(cause, err)
+	}
+
+	return ok, nil
+}
+
+// resolveRefs replaces references to other fields in the query value, see types.Ref, by the values of those fields.
+//
+//	Reports false when a referenced field is nil, which, like a nil field, never matches.
+func resolveRefs(v reflect.Value, tagKeys []string, q t.Query) (t.Query, bool, error) {
+
+	resolve := func(value any) (any, bool, error) {
+		ref, ok := value.(t.Ref)
+		if !ok {
+			return value, true, nil
+		}
+		field, found := fieldByTag(v, tagKeys, string(ref))
+		if !found {
+			cause := fmt.Sprintf("(unknown reference '%s')", ref)
+			return nil, false, e.Wrap(cause, e.ErrBadRequest)
+		}
+		for field.Kind() == reflect.Pointer && !field.IsNil() {
+			field = field.Elem()
+		}
+		if reflection.Nillable(field) && field.IsNil() {
+			return nil, false, nil
+		}
+		return field.Interface(), true, nil
+	}
+
+	elements, isList := q.Value.([]any)
+	if !isList {
+		value, found, err := resolve(q.Value)
+		q.Value = value
+		return q, found, err
+	}
+
+	resolved := make([]any, len(elements))
+	for i := range elements {
+		value, found, err := resolve(elements[i])
+		if err != nil || !found {
+			return q, found, err
+		}
+		resolved[i] = value
+	}
+	q.Value = resolved
+
+	return q, true, nil
+}
+
+// negations maps the negated operators to the operator they negate.
+var negations = map[string]string{
+	"NE":  "EQ",
+	"NIN": "IN",
+}
+
+// matchElements reports whether any element of the slice or array 'field' matches the query.
+//
+//	The negated operators match when no element matches the operator they negate, so 'tags NE x' means x is not a tag.
+func matchElements(field reflect.Value, q t.Query) (bool, error) {
+
+	positive := q
+	if op, found := negations[q.Operator]; found {
+		positive.Operator = op
+	}
+
+	found := false
+	for i := 0; i < field.Len() && !found; i++ {
+
+		element := field.Index(i)
+		for element.Kind() == reflect.Pointer && !element.IsNil() {
+			element = element.Elem()
+		}
+		if reflection.Nillable(element) && element.IsNil() {
+			continue
+		}
+
+		ok, err := matchValue(element, positive)
+		if err != nil {
+			return false, err
+		}
+		found = ok
+	}
+
+	return found == (positive.Operator == q.Operator), nil
+}
+
+func matchValue(field reflect.Value, q t.Query) (bool, error) {
+
+	switch q.Operator {
+
+	case "IN", "NIN":
+		elements, ok := q.Value.([]any)
+		if !ok {
+			return false, e.Wrap("(value is not a list)", e.ErrBadRequest)
+		}
+		in := false
+		for _, element := range elements {
+			c, err := compare(field, reflect.ValueOf(element))
+			if err != nil {
+				return false, err
+			}
+			if c == 0 {
+				in = true
+				break
+			}
+		}
+		return in == (q.Operator == "IN"), nil
+
+	case "BETWEEN":
+		bounds, ok := q.Value.([]any)
+		if !ok || len(bounds) != 2 {
+			return false, e.Wrap("(value is not a list of two bounds)", e.ErrBadRequest)
+		}
+		lower, err := compare(field, reflect.ValueOf(bounds[0]))
+		if err != nil {
+			return false, err
+		}
+		upper, err := compare(field, reflect.ValueOf(bounds[1]))
+		if err != nil {
+			return false, err
+		}
+		return lower >= 0 && upper <= 0, nil
+
+	case "CONTAINS", "PREFIX", "SUFFIX", "ICONTAINS", "IPREFIX", "ISUFFIX":
+		s, ok := q.Value.(string)
+		if !ok || field.Kind() != reflect.String {
+			cause := fmt.Sprintf("(operator '%s' requires string values)", q.Operator)
+			return false, e.Wrap(cause, e.ErrBadRequest)
+		}
+		f := field.String()
+		op := q.Operator
+		if strings.HasPrefix(op, "I") {
+			f, s = strings.ToLower(f), strings.ToLower(s)
+			op = op[1:]
+		}
+		switch op {
+		case "CONTAINS":
+			return strings.Contains(f, s), nil
+		case "PREFIX":
+			return strings.HasPrefix(f, s), nil
+		default:
+			return strings.HasSuffix(f, s), nil
+		}
+	}
+
+	c, err := compare(field, reflect.ValueOf(q.Value))
+	if err != nil {
+		return false, err
+	}
+
+	switch q.Operator {
+	case "EQ":
+		return c == 0, nil
+	case "NE":
+		return c != 0, nil
+	case "LT":
+		return c < 0, nil
+	case "GT":
+		return c > 0, nil
+	case "LE":
+		return c <= 0, nil
+	case "GE":
+		return c >= 0, nil
+	default:
+		cause := fmt.Sprintf("(unsupported operator '%s')", q.Operator)
+		return false, e.Wrap(cause, e.ErrBadRequest)
+	}
+}
+
+// compare returns -1, 0 or 1 depending on whether 'a' is less than, equal to or greater than 'b'.
+//
+//	Booleans are ordered false < true, so that every operator has a defined meaning.
+func compare(a, b reflect.Value) (int, error) {
+
+	mismatch := func() (int, error) {
+		cause := fmt.Sprintf("(cannot compare '%s' with '%v')", a.Type(), b)
+		return 0, e.Wrap(cause, e.ErrBadRequest)
+	}
+
+	if !b.IsValid() {
+		return mismatch()
+	}
+	for b.Kind() == reflect.Pointer && !b.IsNil() {
+		b = b.Elem()
+	}
+
+	if a.Type() == timeType {
+		if b.Type() != timeType {
+			return mismatch()
+		}
+		at := a.Interface().(time.Time)
+		bt := b.Interface().(time.Time)
+		switch {
+		case at.Before(bt):
+			return -1, nil
+		case at.After(bt):
+			return 1, nil
+		default:
+			return 0, nil
+		}
+	}
+
+	switch a.Kind() {
+
+	case reflect.Bool:
+		if b.Kind() != reflect.Bool {
+			return mismatch()
+		}
+		ai, bi := 0, 0
+		if a.Bool() {
+			ai = 1
+		}
+		if b.Bool() {
+			bi = 1
+		}
+		return order(ai, bi), nil
+
+	case reflect.String:
+		if b.Kind() != reflect.String {
+			return mismatch()
+		}
+		return order(a.String(), b.String()), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		if a.CanInt() && b.CanInt() {
+			return order(a.Int(), b.Int()), nil
+		}
+		if a.CanUint() && b.CanUint() {
+			return order(a.Uint(), b.Uint()), nil
+		}
+		af, aok := toFloat(a)
+		bf, bok := toFloat(b)
+		if !aok || !bok {
+			return mismatch()
+		}
+		return order(af, bf), nil
+
+	default:
+		return mismatch()
+	}
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+func order[T int | int64 | uint64 | float64 | string](a, b T) int {
+
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isNumber(v reflect.Value) bool {
+
+	_, ok := toFloat(v)
+	return ok
+}
@@ -0,0 +1,336 @@
+package daos
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	t "github.com/pergamenum/go-consensus-standards/types"
+)
+
+// Memory is a thread-safe, in-memory implementation of interfaces.DAO.
+//
+// It is the reference for what a query means: other DAOs are expected to return the same entities for the same queries.
+//
+// Entities are stored and returned as shallow copies. Update replaces the pointers it sets rather than writing
+// through them, so callers may read what entities point to concurrently, but must not modify it.
+type Memory[E any] struct {
+	mu       sync.RWMutex
+	entities map[string]E
+	tagKeys  []string
+}
+
+type MemoryConfig struct {
+	// TagKeys are the struct tag keys used to resolve query and update keys, in order of precedence.
+	// Defaults to DefaultTagKeys.
+	TagKeys []string
+}
+
+func NewMemory[E any](conf MemoryConfig) *Memory[E] {
+
+	tagKeys := conf.TagKeys
+	if len(tagKeys) == 0 {
+		tagKeys = DefaultTagKeys
+	}
+
+	return &Memory[E]{
+		entities: map[string]E{},
+		tagKeys:  tagKeys,
+	}
+}
+
+func (m *Memory[E]) Create(_ context.Context, id string, entity E) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, found := m.entities[id]; found {
+		cause := fmt.Sprintf("(id '%s' already exists)", id)
+		return e.Wrap(cause, e.ErrConflict)
+	}
+
+	m.entities[id] = entity
+
+	return nil
+}
+
+func (m *Memory[E]) Read(_ context.Context, id string) (entity E, err error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entity, found := m.entities[id]
+	if !found {
+		cause := fmt.Sprintf("(id '%s' does not exist)", id)
+		return entity, e.Wrap(cause, e.ErrNotFound)
+	}
+
+	return entity, nil
+}
+
+func (m *Memory[E]) Update(_ context.Context, id string, update t.Update) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entity, found := m.entities[id]
+	if !found {
+		cause := fmt.Sprintf("(id '%s' does not exist)", id)
+		return e.Wrap(cause, e.ErrNotFound)
+	}
+
+	// Work on a copy, so that a failed update leaves the stored entity untouched.
+	v := reflect.ValueOf(&entity).Elem()
+	if v.Kind() != reflect.Struct {
+		cause := fmt.Sprintf("(entity must be a struct, got: '%s')", v.Kind())
+		return e.Wrap(cause, e.ErrInternal)
+	}
+
+	for key, value := range update {
+
+		field, found := settableFieldByTag(v, m.tagKeys, key)
+		if !found {
+			cause := fmt.Sprintf("(unknown update key '%s')", key)
+			return e.Wrap(cause, e.ErrBadRequest)
+		}
+
+		err := assign(field, value)
+		if err != nil {
+			cause := fmt.Sprintf("(update key '%s')", key)
+			return e.Wrap(cause, err)
+		}
+	}
+
+	m.entities[id] = entity
+
+	return nil
+}
+
+func (m *Memory[E]) Delete(_ context.Context, id string) error {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, found := m.entities[id]; !found {
+		cause := fmt.Sprintf("(id '%s' does not exist)", id)
+		return e.Wrap(cause, e.ErrNotFound)
+	}
+
+	delete(m.entities, id)
+
+	return nil
+}
+
+// Search returns every entity matching all queries, ordered by id.
+func (m *Memory[E]) Search(_ context.Context, queries []t.Query) ([]E, error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.search(queries, nil)
+}
+
+// SearchPage returns a single page of the entities matching all queries and the filter, ordered by the sort keys and then by id.
+//
+//	Nil pointer fields sort before any value.
+func (m *Memory[E]) SearchPage(_ context.Context, queries []t.Query, opts t.SearchOptions) (page t.Page[E], err error) {
+
+	if _, err := opts.Start(); err != nil {
+		return page, err
+	}
+
+	var zero E
+	zv := reflect.ValueOf(&zero).Elem()
+	for _, s := range opts.Sort {
+		if zv.Kind() != reflect.Struct {
+			break
+		}
+		if _, found := fieldByTag(zv, m.tagKeys, s.Key); !found {
+			cause := fmt.Sprintf("(unknown sort key '%s')", s.Key)
+			return page, e.Wrap(cause, e.ErrBadRequest)
+		}
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	es, err := m.search(queries, opts.Filter)
+	if err != nil {
+		return page, err
+	}
+
+	// A stable sort keeps the id order among equal sort keys.
+	var sortErr error
+	sort.SliceStable(es, func(a, b int) bool {
+		c, err := m.compareBy(es[a], es[b], opts.Sort)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return page, sortErr
+	}
+
+	return t.PageOf(es, opts)
+}
+
+func (m *Memory[E]) search(queries []t.Query, filter *t.Expression) ([]E, error) {
+
+	ids := make([]string, 0, len(m.entities))
+	for id := range m.entities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var es []E
+	for _, id := range ids {
+
+		entity := m.entities[id]
+		v := reflect.ValueOf(&entity).Elem()
+		if v.Kind() != reflect.Struct {
+			cause := fmt.Sprintf("(entity must be a struct, got: '%s')", v.Kind())
+			return nil, e.Wrap(cause, e.ErrInternal)
+		}
+
+		ok, err := matchAll(v, m.tagKeys, queries)
+		if err != nil {
+			return nil, err
+		}
+		if ok && filter != nil {
+			ok, err = matchExpression(v, m.tagKeys, *filter)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ok {
+			es = append(es, entity)
+		}
+	}
+
+	return es, nil
+}
+
+// compareBy compares two entities by each sort key in turn.
+func (m *Memory[E]) compareBy(a, b E, sorts []t.Sort) (int, error) {
+
+	av := reflect.ValueOf(&a).Elem()
+	bv := reflect.ValueOf(&b).Elem()
+	for _, s := range sorts {
+
+		af, found := fieldByTag(av, m.tagKeys, s.Key)
+		if !found {
+			cause := fmt.Sprintf("(unknown sort key '%s')", s.Key)
+			return 0, e.Wrap(cause, e.ErrBadRequest)
+		}
+		bf, _ := fieldByTag(bv, m.tagKeys, s.Key)
+
+		for af.Kind() == reflect.Pointer && !af.IsNil() {
+			af = af.Elem()
+		}
+		for bf.Kind() == reflect.Pointer && !bf.IsNil() {
+			bf = bf.Elem()
+		}
+
+		var c int
+		aNil := af.Kind() == reflect.Pointer
+		bNil := bf.Kind() == reflect.Pointer
+		switch {
+		case aNil && bNil:
+			c = 0
+		case aNil:
+			c = -1
+		case bNil:
+			c = 1
+		default:
+			var err error
+			c, err = compare(af, bf)
+			if err != nil {
+				cause := fmt.Sprintf("(sort key '%s')", s.Key)
+				return 0, e.Wrap(cause, err)
+			}
+		}
+
+		if s.Descending {
+			c = -c
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// assign sets 'field' to 'value', allocating new pointers and converting between compatible kinds as needed.
+func assign(field reflect.Value, value any) error {
+
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Pointer {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	// Pointers are replaced rather than written through: what they point to may be shared with the entity
+	// passed to Create, with those returned by Read, and with the stored entity while the update may still fail.
+	target := field
+	for target.Kind() == reflect.Pointer {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+
+	switch {
+	case v.Type().AssignableTo(target.Type()):
+		target.Set(v)
+	case isNumber(v) && isNumber(target):
+		converted, ok := convertNumber(v, target.Type())
+		if !ok {
+			cause := fmt.Sprintf("(cannot assign '%v' to '%s' without loss)", v, target.Type())
+			return e.Wrap(cause, e.ErrBadRequest)
+		}
+		target.Set(converted)
+	default:
+		cause := fmt.Sprintf("(cannot assign '%s' to '%s')", v.Type(), target.Type())
+		return e.Wrap(cause, e.ErrBadRequest)
+	}
+
+	return nil
+}
+
+// convertNumber converts the number to the numeric type, reporting false when the conversion would lose
+// precision, truncate a fraction, overflow or flip the sign.
+func convertNumber(v reflect.Value, t reflect.Type) (reflect.Value, bool) {
+
+	converted := v.Convert(t)
+	if converted.Convert(v.Type()).Interface() != v.Interface() {
+		return reflect.Value{}, false
+	}
+	if isNegative(converted) != isNegative(v) {
+		return reflect.Value{}, false
+	}
+
+	return converted, true
+}
+
+func isNegative(v reflect.Value) bool {
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() < 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() < 0
+	default:
+		return false
+	}
+}
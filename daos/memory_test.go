@@ -0,0 +1,562 @@
+package daos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	"github.com/pergamenum/go-consensus-standards/types"
+)
+
+type user struct {
+	Name    string    `automap:"name"`
+	Age     int       `json:"age"`
+	Active  bool      `automap:"active"`
+	Mail    *string   `automap:"mail"`
+	Created time.Time `automap:"created"`
+}
+
+func newUsers() *Memory[user] {
+
+	ctx := context.Background()
+	m := NewMemory[user](MemoryConfig{})
+
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	_ = m.Create(ctx, "1", user{Name: "Alice", Age: 30, Active: true, Created: base})
+	_ = m.Create(ctx, "2", user{Name: "Bob", Age: 25, Active: false, Created: base.Add(time.Hour)})
+	_ = m.Create(ctx, "3", user{Name: "Carol", Age: 35, Active: true, Created: base.Add(2 * time.Hour)})
+
+	return m
+}
+
+func ExampleMemory_Search() {
+
+	m := newUsers()
+
+	queries := []types.Query{
+		{Key: "active", Operator: "EQ", Value: true},
+		{Key: "age", Operator: "GE", Value: 30},
+	}
+
+	users, err := m.Search(context.Background(), queries)
+	if err != nil {
+		// Handle error...
+	}
+
+	for _, u := range users {
+		fmt.Println(u.Name, u.Age)
+	}
+	// Output:
+	// Alice 30
+	// Carol 35
+}
+
+func Test_Memory_CRUD(t *testing.T) {
+
+	ctx := context.Background()
+	m := NewMemory[user](MemoryConfig{})
+
+	err := m.Create(ctx, "1", user{Name: "Alice"})
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+
+	err = m.Create(ctx, "1", user{Name: "Alice"})
+	if !errors.Is(err, e.ErrConflict) {
+		fmt.Println("Create should report duplicate ids as a conflict, got:", err)
+		t.Fail()
+	}
+
+	err = m.Update(ctx, "1", map[string]any{"name": "Alicia", "age": int64(31), "mail": "a@b.c"})
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+
+	u, err := m.Read(ctx, "1")
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	if u.Name != "Alicia" || u.Age != 31 || u.Mail == nil || *u.Mail != "a@b.c" {
+		fmt.Printf("Update failed to apply: %+v\n", u)
+		t.Fail()
+	}
+
+	err = m.Delete(ctx, "1")
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+
+	_, err = m.Read(ctx, "1")
+	if !errors.Is(err, e.ErrNotFound) {
+		fmt.Println("Read should report missing ids as not found, got:", err)
+		t.Fail()
+	}
+
+	err = m.Delete(ctx, "1")
+	if !errors.Is(err, e.ErrNotFound) {
+		fmt.Println("Delete should report missing ids as not found, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_Memory_Update_Atomic(t *testing.T) {
+
+	ctx := context.Background()
+	m := newUsers()
+
+	err := m.Update(ctx, "1", map[string]any{"name": "Changed", "unknown": 1})
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("Update should reject unknown keys, got:", err)
+		t.Fail()
+	}
+
+	err = m.Update(ctx, "1", map[string]any{"age": "thirty"})
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("Update should reject mismatched types, got:", err)
+		t.Fail()
+	}
+
+	u, _ := m.Read(ctx, "1")
+	if u.Name != "Alice" || u.Age != 30 {
+		fmt.Printf("A failed update should not alter the entity: %+v\n", u)
+		t.Fail()
+	}
+}
+
+func Test_Memory_Update_Pointers(t *testing.T) {
+
+	type person struct {
+		Name string `json:"name"`
+		Age  *int   `json:"age"`
+	}
+
+	ctx := context.Background()
+	m := NewMemory[person](MemoryConfig{})
+	age := 30
+	_ = m.Create(ctx, "1", person{Name: "Alice", Age: &age})
+
+	err := m.Update(ctx, "1", types.Update{"age": 7, "name": 3})
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("Update should reject mismatched types, got:", err)
+		t.Fail()
+	}
+	p, _ := m.Read(ctx, "1")
+	if *p.Age != 30 || age != 30 {
+		fmt.Println("A failed update should not alter the entity or what it points to, got:", *p.Age, age)
+		t.Fail()
+	}
+
+	err = m.Update(ctx, "1", types.Update{"age": 31})
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	updated, _ := m.Read(ctx, "1")
+	if *updated.Age != 31 || *p.Age != 30 || age != 30 {
+		fmt.Println("Update should not write through pointers shared with Create or Read, got:", *updated.Age, *p.Age, age)
+		t.Fail()
+	}
+}
+
+func Test_Memory_Update_Concurrent_Read(t *testing.T) {
+
+	type person struct {
+		Age *int `json:"age"`
+	}
+
+	ctx := context.Background()
+	m := NewMemory[person](MemoryConfig{})
+	age := 0
+	_ = m.Create(ctx, "1", person{Age: &age})
+
+	// Run with -race: Update must not write to what entities returned by Read point to.
+	p, _ := m.Read(ctx, "1")
+	done := make(chan int)
+	go func() {
+		sum := 0
+		for i := 0; i < 1000; i++ {
+			sum += *p.Age
+		}
+		done <- sum
+	}()
+	for i := 0; i < 1000; i++ {
+		_ = m.Update(ctx, "1", types.Update{"age": i})
+	}
+	if sum := <-done; sum != 0 {
+		fmt.Println("Update should not write through pointers returned by Read, got:", sum)
+		t.Fail()
+	}
+}
+
+func Test_Memory_Search_Operators(t *testing.T) {
+
+	ctx := context.Background()
+	m := newUsers()
+	created := time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		query    types.Query
+		expected int
+	}{
+		{types.Query{Key: "name", Operator: "EQ", Value: "Bob"}, 1},
+		{types.Query{Key: "name", Operator: "NE", Value: "Bob"}, 2},
+		{types.Query{Key: "name", Operator: "LT", Value: "Bob"}, 1},
+		{types.Query{Key: "age", Operator: "GT", Value: 25}, 2},
+		{types.Query{Key: "age", Operator: "LE", Value: int8(30)}, 2},
+		{types.Query{Key: "age", Operator: "GE", Value: 30.5}, 1},
+		{types.Query{Key: "created", Operator: "LT", Value: created}, 1},
+		{types.Query{Key: "created", Operator: "GE", Value: created}, 2},
+		{types.Query{Key: "active", Operator: "NE", Value: true}, 1},
+		{types.Query{Key: "mail", Operator: "EQ", Value: "a@b.c"}, 0},
+		{types.Query{Key: "name", Operator: "IN", Value: []any{"Bob", "Carol", "Dave"}}, 2},
+		{types.Query{Key: "age", Operator: "NIN", Value: []any{25, 35}}, 1},
+		{types.Query{Key: "age", Operator: "BETWEEN", Value: []any{26, 35}}, 2},
+		{types.Query{Key: "name", Operator: "CONTAINS", Value: "o"}, 2},
+		{types.Query{Key: "name", Operator: "ICONTAINS", Value: "A"}, 2},
+		{types.Query{Key: "name", Operator: "PREFIX", Value: "Al"}, 1},
+		{types.Query{Key: "name", Operator: "IPREFIX", Value: "al"}, 1},
+		{types.Query{Key: "name", Operator: "SUFFIX", Value: "ol"}, 1},
+		{types.Query{Key: "name", Operator: "ISUFFIX", Value: "OB"}, 1},
+		{types.Query{Key: "mail", Operator: "ISNULL"}, 3},
+		{types.Query{Key: "mail", Operator: "NOTNULL"}, 0},
+	}
+
+	for _, test := range tests {
+		us, err := m.Search(ctx, []types.Query{test.query})
+		if err != nil {
+			fmt.Println(test.query, err)
+			t.Fail()
+		}
+		if len(us) != test.expected {
+			fmt.Printf("%v: expected %d, got %d\n", test.query, test.expected, len(us))
+			t.Fail()
+		}
+	}
+
+	bad := []types.Query{
+		{Key: "unknown", Operator: "EQ", Value: 1},
+		{Key: "age", Operator: "XX", Value: 1},
+		{Key: "age", Operator: "EQ", Value: "1"},
+		{Key: "age", Operator: "IN", Value: 1},
+		{Key: "age", Operator: "BETWEEN", Value: []any{1}},
+		{Key: "age", Operator: "CONTAINS", Value: "1"},
+	}
+
+	for _, q := range bad {
+		_, err := m.Search(ctx, []types.Query{q})
+		if !errors.Is(err, e.ErrBadRequest) {
+			fmt.Printf("%v: expected bad request, got: %v\n", q, err)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Memory_SearchPage(t *testing.T) {
+
+	ctx := context.Background()
+	m := newUsers()
+
+	opts := types.SearchOptions{Limit: 2, Sort: []types.Sort{{Key: "age", Descending: true}}}
+	page, err := m.SearchPage(ctx, nil, opts)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if len(page.Items) != 2 || page.Items[0].Name != "Carol" || page.Items[1].Name != "Alice" {
+		fmt.Printf("unexpected first page: %+v\n", page.Items)
+		t.Fail()
+	}
+	if page.Total != 3 || page.NextCursor == "" {
+		fmt.Printf("unexpected first page envelope: %d, '%s'\n", page.Total, page.NextCursor)
+		t.Fail()
+	}
+
+	opts.Cursor = page.NextCursor
+	page, err = m.SearchPage(ctx, nil, opts)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if len(page.Items) != 1 || page.Items[0].Name != "Bob" || page.NextCursor != "" {
+		fmt.Printf("unexpected last page: %+v, '%s'\n", page.Items, page.NextCursor)
+		t.Fail()
+	}
+
+	opts = types.SearchOptions{Offset: 5}
+	page, err = m.SearchPage(ctx, nil, opts)
+	if err != nil || len(page.Items) != 0 || page.Total != 3 {
+		fmt.Printf("an offset past the end should give an empty page: %+v, %v\n", page, err)
+		t.Fail()
+	}
+
+	opts = types.SearchOptions{Offset: 1, Limit: math.MaxInt}
+	page, err = m.SearchPage(ctx, nil, opts)
+	if err != nil || len(page.Items) != 2 || page.NextCursor != "" {
+		fmt.Printf("the largest limit should give the rest of the items: %+v, %v\n", page, err)
+		t.Fail()
+	}
+
+	opts = types.SearchOptions{Sort: []types.Sort{{Key: "unknown"}}}
+	_, err = m.SearchPage(ctx, nil, opts)
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("SearchPage should reject unknown sort keys, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_Memory_SearchPage_Filter(t *testing.T) {
+
+	ctx := context.Background()
+	m := newUsers()
+
+	// (name EQ Alice OR name EQ Bob) AND NOT active EQ false
+	filter := types.And(
+		types.Or(
+			types.Leaf(types.Query{Key: "name", Operator: "EQ", Value: "Alice"}),
+			types.Leaf(types.Query{Key: "name", Operator: "EQ", Value: "Bob"}),
+		),
+		types.Not(types.Leaf(types.Query{Key: "active", Operator: "EQ", Value: false})),
+	)
+
+	page, err := m.SearchPage(ctx, nil, types.SearchOptions{Filter: &filter})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if len(page.Items) != 1 || page.Items[0].Name != "Alice" || page.Total != 1 {
+		fmt.Printf("unexpected page: %+v\n", page)
+		t.Fail()
+	}
+
+	all := types.FromQueries(nil)
+	page, err = m.SearchPage(ctx, nil, types.SearchOptions{Filter: &all})
+	if err != nil || page.Total != 3 {
+		fmt.Printf("an AND without children should match everything: %+v, %v\n", page, err)
+		t.Fail()
+	}
+
+	bad := types.Not(types.Leaf(types.Query{Key: "unknown", Operator: "EQ", Value: 1}))
+	_, err = m.SearchPage(ctx, nil, types.SearchOptions{Filter: &bad})
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("SearchPage should reject unknown keys in the filter, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_Memory_Embedded(t *testing.T) {
+
+	type Audit struct {
+		Owner string `json:"owner"`
+	}
+	type document struct {
+		Title string `json:"title"`
+		*Audit
+	}
+
+	ctx := context.Background()
+	m := NewMemory[document](MemoryConfig{})
+	_ = m.Create(ctx, "1", document{Title: "a", Audit: &Audit{Owner: "Alice"}})
+	_ = m.Create(ctx, "2", document{Title: "b"})
+
+	found, err := m.Search(ctx, []types.Query{{Key: "owner", Operator: "EQ", Value: "Alice"}})
+	if err != nil || len(found) != 1 || found[0].Title != "a" {
+		fmt.Println("Search failed to match a promoted field, got:", found, err)
+		t.Fail()
+	}
+
+	found, err = m.Search(ctx, []types.Query{{Key: "owner", Operator: "ISNULL"}})
+	if err != nil || len(found) != 1 || found[0].Title != "b" {
+		fmt.Println("Fields behind a nil embedded pointer should be null, got:", found, err)
+		t.Fail()
+	}
+
+	err = m.Update(ctx, "2", types.Update{"owner": "Bob"})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	d, _ := m.Read(ctx, "2")
+	if d.Audit == nil || d.Owner != "Bob" {
+		fmt.Println("Update failed to allocate the embedded struct, got:", d)
+		t.Fail()
+	}
+}
+
+func Test_Memory_Nested(t *testing.T) {
+
+	type Address struct {
+		City string `json:"city"`
+	}
+	type customer struct {
+		Name    string   `json:"name"`
+		Address *Address `json:"address"`
+	}
+
+	ctx := context.Background()
+	m := NewMemory[customer](MemoryConfig{})
+	oslo := &Address{City: "Oslo"}
+	_ = m.Create(ctx, "1", customer{Name: "Alice", Address: oslo})
+	_ = m.Create(ctx, "2", customer{Name: "Bob"})
+
+	found, err := m.Search(ctx, []types.Query{{Key: "address.city", Operator: "EQ", Value: "Oslo"}})
+	if err != nil || len(found) != 1 || found[0].Name != "Alice" {
+		fmt.Println("Search failed to match a nested field, got:", found, err)
+		t.Fail()
+	}
+
+	err = m.Update(ctx, "1", types.Update{"address.city": "Bergen"})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	c, _ := m.Read(ctx, "1")
+	if c.Address.City != "Bergen" || oslo.City != "Oslo" {
+		fmt.Println("Update should copy nested pointers instead of writing through them, got:", c.Address, oslo)
+		t.Fail()
+	}
+
+	err = m.Update(ctx, "2", types.Update{"address.city": "Bergen"})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	c, _ = m.Read(ctx, "2")
+	if c.Address == nil || c.Address.City != "Bergen" {
+		fmt.Println("Update failed to allocate a nil nested struct, got:", c)
+		t.Fail()
+	}
+}
+
+func Test_Memory_Field_Types(t *testing.T) {
+
+	type Status string
+	type ticket struct {
+		Status   Status   `json:"status"`
+		Assignee *int     `json:"assignee"`
+		Labels   []string `json:"labels"`
+	}
+
+	ctx := context.Background()
+	m := NewMemory[ticket](MemoryConfig{})
+	seven := 7
+	_ = m.Create(ctx, "1", ticket{Status: "open", Assignee: &seven, Labels: []string{"bug", "ui"}})
+	_ = m.Create(ctx, "2", ticket{Status: "closed", Labels: []string{"ui"}})
+
+	tests := []struct {
+		query types.Query
+		want  int
+	}{
+		{query: types.Query{Key: "status", Operator: "EQ", Value: Status("open")}, want: 1},
+		{query: types.Query{Key: "assignee", Operator: "GE", Value: 7}, want: 1},
+		{query: types.Query{Key: "labels", Operator: "EQ", Value: "ui"}, want: 2},
+		{query: types.Query{Key: "labels", Operator: "NE", Value: "bug"}, want: 1},
+		{query: types.Query{Key: "labels", Operator: "IN", Value: []any{"bug", "x"}}, want: 1},
+		{query: types.Query{Key: "labels", Operator: "NIN", Value: []any{"bug", "x"}}, want: 1},
+	}
+
+	for _, tc := range tests {
+		found, err := m.Search(ctx, []types.Query{tc.query})
+		if err != nil || len(found) != tc.want {
+			fmt.Printf("%v: got %d matches (%v), want %d\n", tc.query, len(found), err, tc.want)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Memory_Search_Refs(t *testing.T) {
+
+	type event struct {
+		Name    string     `json:"name"`
+		Created time.Time  `json:"created"`
+		Updated *time.Time `json:"updated"`
+	}
+
+	ctx := context.Background()
+	m := NewMemory[event](MemoryConfig{})
+	base := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := base.Add(time.Hour)
+	_ = m.Create(ctx, "1", event{Name: "edited", Created: base, Updated: &later})
+	_ = m.Create(ctx, "2", event{Name: "untouched", Created: base, Updated: &base})
+	_ = m.Create(ctx, "3", event{Name: "new", Created: base})
+
+	found, err := m.Search(ctx, []types.Query{{Key: "updated", Operator: "GT", Value: types.Ref("created")}})
+	if err != nil || len(found) != 1 || found[0].Name != "edited" {
+		fmt.Println("Search failed to compare a field with another, got:", found, err)
+		t.Fail()
+	}
+
+	found, err = m.Search(ctx, []types.Query{{Key: "created", Operator: "EQ", Value: types.Ref("updated")}})
+	if err != nil || len(found) != 1 || found[0].Name != "untouched" {
+		fmt.Println("A nil referenced field should never match, got:", found, err)
+		t.Fail()
+	}
+}
+
+func Test_Memory_Update_Lossy(t *testing.T) {
+
+	type account struct {
+		Balance uint    `json:"balance"`
+		Count   int8    `json:"count"`
+		Rate    float64 `json:"rate"`
+		secret  string  `automap:"secret"`
+	}
+
+	ctx := context.Background()
+	m := NewMemory[account](MemoryConfig{})
+	_ = m.Create(ctx, "1", account{secret: "s"})
+
+	rejected := []types.Update{
+		{"count": 3.7},
+		{"count": 300},
+		{"balance": -1},
+		{"secret": "x"},
+	}
+	for _, update := range rejected {
+		err := m.Update(ctx, "1", update)
+		if !errors.Is(err, e.ErrBadRequest) {
+			fmt.Printf("%v: expected a bad request, got: %v\n", update, err)
+			t.Fail()
+		}
+	}
+
+	err := m.Update(ctx, "1", types.Update{"count": 3.0, "balance": int64(5), "rate": 2})
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	a, _ := m.Read(ctx, "1")
+	if a.Count != 3 || a.Balance != 5 || a.Rate != 2 {
+		fmt.Println("Update should convert exact numbers, got:", a)
+		t.Fail()
+	}
+
+	_, err = m.Search(ctx, []types.Query{{Key: "secret", Operator: "EQ", Value: "s"}})
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("Search should not match unexported fields, got:", err)
+		t.Fail()
+	}
+}
+
+func Benchmark_Memory_SearchPage_Sorted(b *testing.B) {
+
+	ctx := context.Background()
+	m := NewMemory[user](MemoryConfig{})
+	for i := 0; i < 1000; i++ {
+		_ = m.Create(ctx, fmt.Sprint(i), user{Name: fmt.Sprint(i % 37), Age: i % 91})
+	}
+	queries := []types.Query{{Key: "age", Operator: "GE", Value: 10}}
+	opts := types.SearchOptions{Sort: []types.Sort{{Key: "name"}, {Key: "age", Descending: true}}, Limit: 20}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = m.SearchPage(ctx, queries, opts)
+	}
+}
@@ -0,0 +1,420 @@
+package daos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+	t "github.com/pergamenum/go-consensus-standards/types"
+)
+
+// SQL is an implementation of interfaces.DAO backed by database/sql.
+//
+// Table columns are derived from the entity's struct tags, so every tagged field must have a matching column.
+type SQL[E any] struct {
+	db       *sql.DB
+	table    string
+	dialect  Dialect
+	idColumn string
+	columns  []string
+	indices  [][]int
+	allowed  map[string]string
+}
+
+type SQLConfig struct {
+	DB      *sql.DB
+	Table   string
+	Dialect Dialect
+	// TagKey is the struct tag key holding column names. Defaults to "db".
+	TagKey string
+	// IDColumn is the column holding the id. Defaults to "id".
+	// The entity may, but need not, have a field tagged with it.
+	IDColumn string
+}
+
+func NewSQL[E any](conf SQLConfig) (*SQL[E], error) {
+
+	if conf.DB == nil {
+		return nil, fmt.Errorf("(invalid: 'DB was nil')")
+	}
+	if conf.Table == "" {
+		return nil, fmt.Errorf("(invalid: 'Table was empty')")
+	}
+	if conf.Dialect == nil {
+		return nil, fmt.Errorf("(invalid: 'Dialect was nil')")
+	}
+
+	tagKey := conf.TagKey
+	if tagKey == "" {
+		tagKey = "db"
+	}
+	idColumn := conf.IDColumn
+	if idColumn == "" {
+		idColumn = "id"
+	}
+
+	var entity E
+	et := reflect.TypeOf(entity)
+	if et == nil || et.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("(invalid: 'entity must be a struct')")
+	}
+
+	d := &SQL[E]{
+		db:       conf.DB,
+		table:    conf.Table,
+		dialect:  conf.Dialect,
+		idColumn: idColumn,
+		allowed:  map[string]string{},
+	}
+
+	for _, f := range reflection.TaggedFields(et, tagKey) {
+
+		if !f.Field.IsExported() {
+			continue
+		}
+
+		d.columns = append(d.columns, f.Tag)
+		d.indices = append(d.indices, f.Index)
+		d.allowed[f.Tag] = f.Tag
+	}
+
+	if len(d.columns) == 0 {
+		cause := fmt.Sprintf("(invalid: 'entity has no fields tagged with %s')", tagKey)
+		return nil, errors.New(cause)
+	}
+
+	return d, nil
+}
+
+func (d *SQL[E]) Create(ctx context.Context, id string, entity E) error {
+
+	v := reflect.ValueOf(entity)
+
+	columns := []string{d.dialect.Quote(d.idColumn)}
+	placeholders := []string{d.dialect.Placeholder(1)}
+	args := []any{id}
+	for i, c := range d.columns {
+		if c == d.idColumn {
+			continue
+		}
+		columns = append(columns, d.dialect.Quote(c))
+		// Fields promoted through a nil embedded pointer are stored as NULL.
+		var arg any
+		if field, ok := reflection.FieldByIndex(v, d.indices[i]); ok {
+			arg = field.Interface()
+		}
+		args = append(args, arg)
+		placeholders = append(placeholders, d.dialect.Placeholder(len(args)))
+	}
+
+	statement := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		d.dialect.Quote(d.table), strings.Join(columns, ", "), strings.Join(placeholders, ", "),
+	)
+
+	_, err := d.db.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return d.classify(err)
+	}
+
+	return nil
+}
+
+func (d *SQL[E]) Read(ctx context.Context, id string) (entity E, err error) {
+
+	statement := fmt.Sprintf(
+		"%s WHERE %s = %s",
+		d.selectFrom(), d.dialect.Quote(d.idColumn), d.dialect.Placeholder(1),
+	)
+
+	row := d.db.QueryRowContext(ctx, statement, id)
+	err = row.Scan(d.targets(&entity)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		cause := fmt.Sprintf("(id '%s' does not exist)", id)
+		return entity, e.Wrap(cause, e.ErrNotFound)
+	}
+	if err != nil {
+		return entity, e.Wrap(err, e.ErrInternal)
+	}
+
+	return entity, nil
+}
+
+// Update applies the update as a single UPDATE statement, restricted to the entity's columns.
+//
+//	When no row is affected, the row's existence is checked before it is reported as not found.
+func (d *SQL[E]) Update(ctx context.Context, id string, update t.Update) error {
+
+	if len(update) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(update))
+	for k := range update {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var assignments []string
+	var args []any
+	for _, k := range keys {
+
+		column, found := d.allowed[k]
+		if !found || column == d.idColumn {
+			cause := fmt.Sprintf("(invalid update key '%s')", k)
+			return e.Wrap(cause, e.ErrBadRequest)
+		}
+
+		args = append(args, update[k])
+		a := fmt.Sprintf("%s = %s", d.dialect.Quote(column), d.dialect.Placeholder(len(args)))
+		assignments = append(assignments, a)
+	}
+
+	args = append(args, id)
+	statement := fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s = %s",
+		d.dialect.Quote(d.table), strings.Join(assignments, ", "),
+		d.dialect.Quote(d.idColumn), d.dialect.Placeholder(len(args)),
+	)
+
+	result, err := d.db.ExecContext(ctx, statement, args...)
+	if err != nil {
+		return d.classify(err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return e.Wrap(err, e.ErrInternal)
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// Some drivers, such as MySQL's by default, count the rows changed rather than those matched,
+	// so an update writing the values a row already holds affects none.
+	found, err := d.exists(ctx, id)
+	if err != nil {
+		return e.Wrap(err, e.ErrInternal)
+	}
+	if !found {
+		cause := fmt.Sprintf("(id '%s' does not exist)", id)
+		return e.Wrap(cause, e.ErrNotFound)
+	}
+
+	return nil
+}
+
+func (d *SQL[E]) exists(ctx context.Context, id string) (bool, error) {
+
+	statement := fmt.Sprintf(
+		"SELECT 1 FROM %s WHERE %s = %s",
+		d.dialect.Quote(d.table), d.dialect.Quote(d.idColumn), d.dialect.Placeholder(1),
+	)
+
+	var one int
+	err := d.db.QueryRowContext(ctx, statement, id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// classify reports unique constraint violations as conflicts, when the dialect can recognize them, and the rest as internal errors.
+func (d *SQL[E]) classify(err error) error {
+
+	if cd, ok := d.dialect.(ConflictDialect); ok && cd.IsConflict(err) {
+		return e.Wrap(err, e.ErrConflict)
+	}
+
+	return e.Wrap(err, e.ErrInternal)
+}
+
+func (d *SQL[E]) Delete(ctx context.Context, id string) error {
+
+	statement := fmt.Sprintf(
+		"DELETE FROM %s WHERE %s = %s",
+		d.dialect.Quote(d.table), d.dialect.Quote(d.idColumn), d.dialect.Placeholder(1),
+	)
+
+	result, err := d.db.ExecContext(ctx, statement, id)
+	if err != nil {
+		return e.Wrap(err, e.ErrInternal)
+	}
+
+	return d.expectAffected(result, id)
+}
+
+// Search returns every entity matching all queries, ordered by id.
+func (d *SQL[E]) Search(ctx context.Context, queries []t.Query) ([]E, error) {
+
+	filter, args, err := d.filter(queries, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := d.selectFrom() + filter + " ORDER BY " + d.dialect.Quote(d.idColumn)
+
+	return d.query(ctx, statement, args)
+}
+
+// SearchPage returns a single page of the entities matching all queries and the filter, ordered by the sort keys and then by id.
+//
+//	Ordering of NULL values is left to the database.
+func (d *SQL[E]) SearchPage(ctx context.Context, queries []t.Query, opts t.SearchOptions) (page t.Page[E], err error) {
+
+	start, err := opts.Start()
+	if err != nil {
+		return page, err
+	}
+
+	var order []string
+	for _, s := range opts.Sort {
+		column, found := d.allowed[s.Key]
+		if !found {
+			cause := fmt.Sprintf("(unknown sort key '%s')", s.Key)
+			return page, e.Wrap(cause, e.ErrBadRequest)
+		}
+		direction := "ASC"
+		if s.Descending {
+			direction = "DESC"
+		}
+		order = append(order, d.dialect.Quote(column)+" "+direction)
+	}
+	order = append(order, d.dialect.Quote(d.idColumn)+" ASC")
+
+	filter, args, err := d.filter(queries, opts.Filter)
+	if err != nil {
+		return page, err
+	}
+
+	count := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", d.dialect.Quote(d.table), filter)
+	err = d.db.QueryRowContext(ctx, count, args...).Scan(&page.Total)
+	if err != nil {
+		return page, e.Wrap(err, e.ErrInternal)
+	}
+
+	statement := d.selectFrom() + filter + " ORDER BY " + strings.Join(order, ", ")
+	// Limit and offset are integers, so they are safe to inline.
+	skip := start
+	if opts.Limit > 0 {
+		statement += fmt.Sprintf(" LIMIT %d OFFSET %d", opts.Limit, start)
+		skip = 0
+	}
+
+	es, err := d.query(ctx, statement, args)
+	if err != nil {
+		return page, err
+	}
+	// Not every dialect supports OFFSET without LIMIT, so those rows are skipped here instead.
+	if skip > len(es) {
+		skip = len(es)
+	}
+
+	page.Items = es[skip:]
+	page.NextCursor = opts.Next(start, len(page.Items), page.Total)
+
+	return page, nil
+}
+
+// filter returns the WHERE clause, including the keyword, for the queries and the optional expression.
+func (d *SQL[E]) filter(queries []t.Query, x *t.Expression) (string, []any, error) {
+
+	where, args, err := Where(d.dialect, d.allowed, queries, 0)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if x != nil {
+		cond, values, err := WhereExpression(d.dialect, d.allowed, *x, len(args))
+		if err != nil {
+			return "", nil, err
+		}
+		if where != "" {
+			where += " AND "
+		}
+		where += cond
+		args = append(args, values...)
+	}
+
+	if where == "" {
+		return "", nil, nil
+	}
+
+	return " WHERE " + where, args, nil
+}
+
+func (d *SQL[E]) query(ctx context.Context, statement string, args []any) ([]E, error) {
+
+	rows, err := d.db.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, e.Wrap(err, e.ErrInternal)
+	}
+	defer rows.Close()
+
+	var es []E
+	for rows.Next() {
+		var entity E
+		err = rows.Scan(d.targets(&entity)...)
+		if err != nil {
+			return nil, e.Wrap(err, e.ErrInternal)
+		}
+		es = append(es, entity)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, e.Wrap(err, e.ErrInternal)
+	}
+
+	return es, nil
+}
+
+func (d *SQL[E]) selectFrom() string {
+
+	quoted := make([]string, len(d.columns))
+	for i, c := range d.columns {
+		quoted[i] = d.dialect.Quote(c)
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), d.dialect.Quote(d.table))
+}
+
+// targets returns pointers to the entity's column fields, in column order, for use with Scan.
+func (d *SQL[E]) targets(entity *E) []any {
+
+	v := reflect.ValueOf(entity).Elem()
+	ts := make([]any, len(d.indices))
+	for i, index := range d.indices {
+		field, ok := reflection.FieldByIndexAlloc(v, index)
+		if !ok {
+			// Unreachable through an unexported nil embedded pointer, so the column is discarded.
+			ts[i] = new(any)
+			continue
+		}
+		ts[i] = field.Addr().Interface()
+	}
+
+	return ts
+}
+
+func (d *SQL[E]) expectAffected(result sql.Result, id string) error {
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return e.Wrap(err, e.ErrInternal)
+	}
+	if n == 0 {
+		cause := fmt.Sprintf("(id '%s' does not exist)", id)
+		return e.Wrap(cause, e.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,369 @@
+package daos
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	"github.com/pergamenum/go-consensus-standards/types"
+)
+
+// fakeResponse is what the fake driver answers to the next statement.
+type fakeResponse struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// fakeDB records every statement and answers with queued responses, so no live database is required.
+type fakeDB struct {
+	statements []string
+	args       [][]any
+	responses  []fakeResponse
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+func (f *fakeDB) next(query string, args []driver.NamedValue) fakeResponse {
+
+	f.statements = append(f.statements, query)
+	var as []any
+	for _, a := range args {
+		as = append(as, a.Value)
+	}
+	f.args = append(f.args, as)
+
+	if len(f.responses) == 0 {
+		return fakeResponse{}
+	}
+	r := f.responses[0]
+	f.responses = f.responses[1:]
+	return r
+}
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return nil, errors.New("unsupported") }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("unsupported")
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) {
+	return nil, errors.New("unsupported")
+}
+
+func (s fakeStmt) ExecContext(_ context.Context, args []driver.NamedValue) (driver.Result, error) {
+	r := s.db.next(s.query, args)
+	if r.err != nil {
+		return nil, r.err
+	}
+	return driver.RowsAffected(r.affected), nil
+}
+
+func (s fakeStmt) QueryContext(_ context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	r := s.db.next(s.query, args)
+	return &fakeRows{columns: r.columns, rows: r.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type account struct {
+	ID    string  `db:"id"`
+	Name  string  `db:"name"`
+	Age   int     `db:"age"`
+	Email *string `db:"email"`
+	Note  string
+}
+
+func newAccounts(f *fakeDB) *SQL[account] {
+
+	d, err := NewSQL[account](SQLConfig{
+		DB:      sql.OpenDB(f),
+		Table:   "accounts",
+		Dialect: Postgres,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func Test_SQL_Create(t *testing.T) {
+
+	f := &fakeDB{}
+	d := newAccounts(f)
+
+	err := d.Create(context.Background(), "42", account{ID: "ignored", Name: "Alice", Age: 30})
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+
+	expected := `INSERT INTO "accounts" ("id", "name", "age", "email") VALUES ($1, $2, $3, $4)`
+	if len(f.statements) != 1 || f.statements[0] != expected {
+		fmt.Println("unexpected statements:", f.statements)
+		t.FailNow()
+	}
+	args := fmt.Sprint(f.args[0])
+	if args != "[42 Alice 30 <nil>]" {
+		fmt.Println("unexpected args:", args)
+		t.Fail()
+	}
+}
+
+// pgError mimics the errors of Postgres drivers, which expose the SQLSTATE code.
+type pgError string
+
+func (p pgError) Error() string    { return "pg error " + string(p) }
+func (p pgError) SQLState() string { return string(p) }
+
+func Test_SQL_Create_Conflict(t *testing.T) {
+
+	f := &fakeDB{responses: []fakeResponse{
+		{err: pgError("23505")},
+		{err: pgError("08006")},
+	}}
+	d := newAccounts(f)
+
+	err := d.Create(context.Background(), "42", account{Name: "Alice"})
+	if !errors.Is(err, e.ErrConflict) {
+		fmt.Println("Create should report a duplicate id as a conflict, got:", err)
+		t.Fail()
+	}
+
+	err = d.Create(context.Background(), "43", account{Name: "Bob"})
+	if !errors.Is(err, e.ErrInternal) {
+		fmt.Println("Create should report other driver errors as internal, got:", err)
+		t.Fail()
+	}
+
+	if !MySQL.(ConflictDialect).IsConflict(errors.New("Error 1062 (23000): Duplicate entry '42' for key 'PRIMARY'")) {
+		fmt.Println("MySQL should recognize duplicate entries.")
+		t.Fail()
+	}
+	if !SQLite.(ConflictDialect).IsConflict(errors.New("UNIQUE constraint failed: accounts.id")) {
+		fmt.Println("SQLite should recognize unique constraint violations.")
+		t.Fail()
+	}
+}
+
+func Test_SQL_Read(t *testing.T) {
+
+	f := &fakeDB{responses: []fakeResponse{
+		{
+			columns: []string{"id", "name", "age", "email"},
+			rows:    [][]driver.Value{{"42", "Alice", int64(30), "a@b.c"}},
+		},
+		{columns: []string{"id", "name", "age", "email"}},
+	}}
+	d := newAccounts(f)
+	ctx := context.Background()
+
+	a, err := d.Read(ctx, "42")
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if a.ID != "42" || a.Name != "Alice" || a.Age != 30 || a.Email == nil || *a.Email != "a@b.c" {
+		fmt.Printf("unexpected entity: %+v\n", a)
+		t.Fail()
+	}
+
+	expected := `SELECT "id", "name", "age", "email" FROM "accounts" WHERE "id" = $1`
+	if f.statements[0] != expected {
+		fmt.Println("unexpected statement:", f.statements[0])
+		t.Fail()
+	}
+
+	_, err = d.Read(ctx, "43")
+	if !errors.Is(err, e.ErrNotFound) {
+		fmt.Println("Read should report missing rows as not found, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_SQL_Update(t *testing.T) {
+
+	f := &fakeDB{responses: []fakeResponse{
+		{affected: 1},
+		{affected: 0},
+		{columns: []string{"1"}},
+		{affected: 0},
+		{columns: []string{"1"}, rows: [][]driver.Value{{int64(1)}}},
+	}}
+	d := newAccounts(f)
+	ctx := context.Background()
+
+	err := d.Update(ctx, "42", types.Update{"name": "Alicia", "age": 31})
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	expected := `UPDATE "accounts" SET "age" = $1, "name" = $2 WHERE "id" = $3`
+	if f.statements[0] != expected || fmt.Sprint(f.args[0]) != "[31 Alicia 42]" {
+		fmt.Println("unexpected statement:", f.statements[0], f.args[0])
+		t.Fail()
+	}
+
+	err = d.Update(ctx, "43", types.Update{"name": "Nobody"})
+	if !errors.Is(err, e.ErrNotFound) {
+		fmt.Println("Update should report missing rows as not found, got:", err)
+		t.Fail()
+	}
+	if f.statements[2] != `SELECT 1 FROM "accounts" WHERE "id" = $1` || fmt.Sprint(f.args[2]) != "[43]" {
+		fmt.Println("Update should check whether an unaffected row exists, got:", f.statements[2], f.args[2])
+		t.Fail()
+	}
+
+	// MySQL counts changed rows, so writing identical values affects none.
+	err = d.Update(ctx, "42", types.Update{"name": "Alicia"})
+	if err != nil {
+		fmt.Println("Update should accept unchanged rows that exist, got:", err)
+		t.Fail()
+	}
+
+	for _, bad := range []types.Update{{"Note": "x"}, {"id": "1"}, {`name" = 1; --`: "x"}} {
+		err = d.Update(ctx, "42", bad)
+		if !errors.Is(err, e.ErrBadRequest) {
+			fmt.Printf("%v: expected bad request, got: %v\n", bad, err)
+			t.Fail()
+		}
+	}
+	if len(f.statements) != 5 {
+		fmt.Println("rejected updates should not reach the database:", f.statements)
+		t.Fail()
+	}
+}
+
+func Test_SQL_Delete(t *testing.T) {
+
+	f := &fakeDB{responses: []fakeResponse{{affected: 1}, {affected: 0}}}
+	d := newAccounts(f)
+	ctx := context.Background()
+
+	err := d.Delete(ctx, "42")
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	if f.statements[0] != `DELETE FROM "accounts" WHERE "id" = $1` {
+		fmt.Println("unexpected statement:", f.statements[0])
+		t.Fail()
+	}
+
+	err = d.Delete(ctx, "42")
+	if !errors.Is(err, e.ErrNotFound) {
+		fmt.Println("Delete should report unaffected rows as not found, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_SQL_Search(t *testing.T) {
+
+	f := &fakeDB{responses: []fakeResponse{{
+		columns: []string{"id", "name", "age", "email"},
+		rows: [][]driver.Value{
+			{"1", "Alice", int64(30), nil},
+			{"3", "Carol", int64(35), nil},
+		},
+	}}}
+	d := newAccounts(f)
+	ctx := context.Background()
+
+	queries := []types.Query{{Key: "age", Operator: "GE", Value: 30}}
+	as, err := d.Search(ctx, queries)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if len(as) != 2 || as[1].Name != "Carol" || as[0].Email != nil {
+		fmt.Printf("unexpected entities: %+v\n", as)
+		t.Fail()
+	}
+
+	expected := `SELECT "id", "name", "age", "email" FROM "accounts" WHERE "age" >= $1 ORDER BY "id"`
+	if f.statements[0] != expected {
+		fmt.Println("unexpected statement:", f.statements[0])
+		t.Fail()
+	}
+
+	_, err = d.Search(ctx, []types.Query{{Key: "Note", Operator: "EQ", Value: "x"}})
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("Search should reject keys that are not columns, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_SQL_SearchPage(t *testing.T) {
+
+	f := &fakeDB{responses: []fakeResponse{
+		{columns: []string{"count"}, rows: [][]driver.Value{{int64(3)}}},
+		{
+			columns: []string{"id", "name", "age", "email"},
+			rows:    [][]driver.Value{{"3", "Carol", int64(35), nil}, {"1", "Alice", int64(30), nil}},
+		},
+	}}
+	d := newAccounts(f)
+
+	queries := []types.Query{{Key: "age", Operator: "GE", Value: 25}}
+	opts := types.SearchOptions{Limit: 2, Sort: []types.Sort{{Key: "age", Descending: true}}}
+	page, err := d.SearchPage(context.Background(), queries, opts)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if len(page.Items) != 2 || page.Total != 3 || page.NextCursor == "" {
+		fmt.Printf("unexpected page: %+v\n", page)
+		t.Fail()
+	}
+
+	expected := []string{
+		`SELECT COUNT(*) FROM "accounts" WHERE "age" >= $1`,
+		`SELECT "id", "name", "age", "email" FROM "accounts" WHERE "age" >= $1 ORDER BY "age" DESC, "id" ASC LIMIT 2 OFFSET 0`,
+	}
+	if fmt.Sprint(f.statements) != fmt.Sprint(expected) {
+		fmt.Println("unexpected statements:", f.statements)
+		t.Fail()
+	}
+
+	opts = types.SearchOptions{Sort: []types.Sort{{Key: "Note"}}}
+	_, err = d.SearchPage(context.Background(), nil, opts)
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("SearchPage should reject sort keys that are not columns, got:", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,335 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+	t "github.com/pergamenum/go-consensus-standards/types"
+)
+
+// Dialect describes the SQL syntax differences that matter when compiling queries.
+type Dialect interface {
+	// Placeholder returns the n:th (1-based) positional parameter.
+	Placeholder(n int) string
+	// Quote returns the identifier quoted for safe use as a column or table name.
+	Quote(identifier string) string
+}
+
+// ConflictDialect is implemented by dialects that can tell unique constraint violations from other driver errors.
+//
+//	The SQL DAO reports such violations as ehandler.ErrConflict, and any other driver error as ehandler.ErrInternal.
+//	All the dialects of this package implement it.
+type ConflictDialect interface {
+	Dialect
+	// IsConflict reports whether the driver error is a unique constraint violation, such as a duplicate id.
+	IsConflict(err error) bool
+}
+
+// MatchDialect is implemented by dialects whose LIKE ignores case, so that CONTAINS, PREFIX and SUFFIX match case-sensitively
+// all the same, as they do in Memory.
+//
+//	MySQL, with its default case-insensitive collations, and SQLite implement it. The LIKE of Postgres already respects case.
+type MatchDialect interface {
+	Dialect
+	// Match returns the case-sensitive condition of the CONTAINS, PREFIX or SUFFIX operator on the quoted column,
+	// with the placeholder standing for the pattern it returns, built from the string.
+	Match(operator, column, placeholder, s string) (condition string, pattern string)
+}
+
+var (
+	Postgres Dialect = postgres{}
+	MySQL    Dialect = mysql{}
+	SQLite   Dialect = sqlite{}
+)
+
+type postgres struct{}
+
+func (postgres) Placeholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func (postgres) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+// IsConflict looks for SQLSTATE 23505 (unique_violation), as exposed by both pgx and lib/pq.
+func (postgres) IsConflict(err error) bool {
+
+	var state interface{ SQLState() string }
+	return errors.As(err, &state) && state.SQLState() == "23505"
+}
+
+type mysql struct{}
+
+func (mysql) Placeholder(int) string {
+	return "?"
+}
+
+func (mysql) Quote(identifier string) string {
+	return quote(identifier, "`")
+}
+
+// IsConflict looks for error 1062 (ER_DUP_ENTRY), which the driver only exposes in the message.
+func (mysql) IsConflict(err error) bool {
+	return strings.Contains(err.Error(), "Error 1062")
+}
+
+// Match compares the bytes of the column and the LIKE pattern, regardless of the column's collation.
+func (mysql) Match(operator, column, placeholder, s string) (string, string) {
+	return fmt.Sprintf("CAST(%s AS BINARY) LIKE CAST(%s AS BINARY) ESCAPE '!'", column, placeholder), likePattern(operator, s)
+}
+
+type sqlite struct{}
+
+func (sqlite) Placeholder(int) string {
+	return "?"
+}
+
+func (sqlite) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+// IsConflict looks for the message SQLite gives unique and primary key violations, shared by its Go drivers.
+func (sqlite) IsConflict(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// Match uses GLOB, which respects case where LIKE ignores it for ASCII letters.
+func (sqlite) Match(operator, column, placeholder, s string) (string, string) {
+
+	pattern := globEscaper.Replace(s)
+	switch operator {
+	case "CONTAINS":
+		pattern = "*" + pattern + "*"
+	case "PREFIX":
+		pattern = pattern + "*"
+	default:
+		pattern = "*" + pattern
+	}
+
+	return fmt.Sprintf("%s GLOB %s", column, placeholder), pattern
+}
+
+// globEscaper escapes the GLOB wildcards by enclosing them in brackets, as GLOB has no escape character.
+var globEscaper = strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]")
+
+// quote wraps every dot-separated part of the identifier in 'q', doubling any embedded 'q'.
+func quote(identifier, q string) string {
+
+	parts := strings.Split(identifier, ".")
+	for i, p := range parts {
+		parts[i] = q + strings.ReplaceAll(p, q, q+q) + q
+	}
+
+	return strings.Join(parts, ".")
+}
+
+var sqlOperators = map[string]string{
+	"EQ": "=",
+	"NE": "<>",
+	"LT": "<",
+	"GT": ">",
+	"LE": "<=",
+	"GE": ">=",
+}
+
+// Columns maps every tagged field of the input struct to a column of the same name.
+//
+//	Intended as the 'columns' argument to Where, when tags and column names coincide.
+func Columns(tagKey string, inputStruct any) map[string]string {
+
+	m := map[string]string{}
+	// Nested struct fields have no column of their own, so only the top-level fields are included.
+	for _, f := range reflection.TaggedFields(reflect.TypeOf(inputStruct), tagKey) {
+		m[f.Tag] = f.Tag
+	}
+
+	return m
+}
+
+// Where compiles the queries into a parameterized WHERE fragment, joined by AND and without the WHERE keyword.
+//
+//	'columns' maps query keys to column names and doubles as an allowlist: any other key is rejected.
+//	'argOffset' is the number of placeholders already used earlier in the statement.
+//	Returns an empty fragment when there are no queries.
+func Where(d Dialect, columns map[string]string, queries []t.Query, argOffset int) (string, []any, error) {
+
+	var conditions []string
+	var args []any
+	for _, q := range queries {
+
+		column, found := columns[q.Key]
+		if !found {
+			cause := fmt.Sprintf("(unknown query key '%s')", q.Key)
+			return "", nil, e.Wrap(cause, e.ErrBadRequest)
+		}
+
+		cond, values, err := condition(d, columns, d.Quote(column), q, argOffset+len(args))
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, cond)
+		args = append(args, values...)
+	}
+
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// WhereExpression compiles the expression tree into a parameterized WHERE fragment, like Where does for a list of queries.
+func WhereExpression(d Dialect, columns map[string]string, x t.Expression, argOffset int) (string, []any, error) {
+
+	switch x.Operator {
+
+	case "":
+		return Where(d, columns, []t.Query{x.Query}, argOffset)
+
+	case "AND", "OR", "NOT":
+		if x.Operator == "AND" && len(x.Children) == 0 {
+			// Matches everything, like an empty list of queries.
+			return "1 = 1", nil, nil
+		}
+		if len(x.Children) == 0 || (x.Operator == "NOT" && len(x.Children) != 1) {
+			cause := fmt.Sprintf("(expression '%s' has an invalid number of children)", x.Operator)
+			return "", nil, e.Wrap(cause, e.ErrBadRequest)
+		}
+
+		var conditions []string
+		var args []any
+		for _, child := range x.Children {
+			cond, values, err := WhereExpression(d, columns, child, argOffset+len(args))
+			if err != nil {
+				return "", nil, err
+			}
+			conditions = append(conditions, cond)
+			args = append(args, values...)
+		}
+
+		if x.Operator == "NOT" {
+			return "NOT (" + conditions[0] + ")", args, nil
+		}
+		return "(" + strings.Join(conditions, " "+x.Operator+" ") + ")", args, nil
+
+	default:
+		cause := fmt.Sprintf("(unsupported expression operator '%s')", x.Operator)
+		return "", nil, e.Wrap(cause, e.ErrBadRequest)
+	}
+}
+
+// condition compiles a single query on the quoted column, numbering its placeholders after 'used'.
+//
+//	References to other fields, see types.Ref, compile to their columns, looked up in 'columns'.
+func condition(d Dialect, columns map[string]string, column string, q t.Query, used int) (string, []any, error) {
+
+	var args []any
+	operand := func(value any) (string, error) {
+		if ref, ok := value.(t.Ref); ok {
+			c, found := columns[string(ref)]
+			if !found {
+				cause := fmt.Sprintf("(unknown reference '%s')", ref)
+				return "", e.Wrap(cause, e.ErrBadRequest)
+			}
+			return d.Quote(c), nil
+		}
+		args = append(args, value)
+		return d.Placeholder(used + len(args)), nil
+	}
+	operands := func(values []any) ([]string, error) {
+		ops := make([]string, len(values))
+		for i := range values {
+			op, err := operand(values[i])
+			if err != nil {
+				return nil, err
+			}
+			ops[i] = op
+		}
+		return ops, nil
+	}
+
+	if op, found := sqlOperators[q.Operator]; found {
+		value, err := operand(q.Value)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s %s %s", column, op, value), args, nil
+	}
+
+	switch q.Operator {
+
+	case "ISNULL":
+		return column + " IS NULL", nil, nil
+
+	case "NOTNULL":
+		return column + " IS NOT NULL", nil, nil
+
+	case "IN", "NIN":
+		elements, ok := q.Value.([]any)
+		if !ok || len(elements) == 0 {
+			return "", nil, e.Wrap("(value is not a non-empty list)", e.ErrBadRequest)
+		}
+		values, err := operands(elements)
+		if err != nil {
+			return "", nil, err
+		}
+		op := "IN"
+		if q.Operator == "NIN" {
+			op = "NOT IN"
+		}
+		return fmt.Sprintf("%s %s (%s)", column, op, strings.Join(values, ", ")), args, nil
+
+	case "BETWEEN":
+		bounds, ok := q.Value.([]any)
+		if !ok || len(bounds) != 2 {
+			return "", nil, e.Wrap("(value is not a list of two bounds)", e.ErrBadRequest)
+		}
+		values, err := operands(bounds)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s BETWEEN %s AND %s", column, values[0], values[1]), args, nil
+
+	case "CONTAINS", "PREFIX", "SUFFIX", "ICONTAINS", "IPREFIX", "ISUFFIX":
+		s, ok := q.Value.(string)
+		if !ok {
+			cause := fmt.Sprintf("(operator '%s' requires a string value)", q.Operator)
+			return "", nil, e.Wrap(cause, e.ErrBadRequest)
+		}
+		op := q.Operator
+		insensitive := strings.HasPrefix(op, "I")
+		op = strings.TrimPrefix(op, "I")
+		if md, ok := d.(MatchDialect); ok && !insensitive {
+			cond, pattern := md.Match(op, column, d.Placeholder(used+1), s)
+			return cond, []any{pattern}, nil
+		}
+		value, _ := operand(likePattern(op, s))
+		if insensitive {
+			return fmt.Sprintf("LOWER(%s) LIKE LOWER(%s) ESCAPE '!'", column, value), args, nil
+		}
+		return fmt.Sprintf("%s LIKE %s ESCAPE '!'", column, value), args, nil
+
+	default:
+		cause := fmt.Sprintf("(unsupported operator '%s')", q.Operator)
+		return "", nil, e.Wrap(cause, e.ErrBadRequest)
+	}
+}
+
+// likePattern returns the LIKE pattern of the CONTAINS, PREFIX or SUFFIX operator for the string, escaped with '!'.
+func likePattern(operator, s string) string {
+
+	pattern := likeEscaper.Replace(s)
+	switch operator {
+	case "CONTAINS":
+		return "%" + pattern + "%"
+	case "PREFIX":
+		return pattern + "%"
+	default:
+		return "%" + pattern
+	}
+}
+
+// likeEscaper escapes the LIKE wildcards with '!', which, unlike a backslash, needs no escaping in any dialect's literals.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
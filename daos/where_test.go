@@ -0,0 +1,203 @@
+package daos
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	"github.com/pergamenum/go-consensus-standards/types"
+)
+
+func ExampleWhere() {
+
+	type User struct {
+		Name string `db:"name"`
+		Age  int    `db:"age"`
+	}
+
+	columns := Columns("db", User{})
+	queries := []types.Query{
+		{Key: "age", Operator: "GE", Value: 30},
+		{Key: "name", Operator: "NE", Value: "Bob"},
+	}
+
+	where, args, err := Where(Postgres, columns, queries, 0)
+	if err != nil {
+		// Handle error...
+	}
+	fmt.Println(where, args)
+
+	where, args, _ = Where(MySQL, columns, queries, 0)
+	fmt.Println(where, args)
+
+	// Output:
+	// "age" >= $1 AND "name" <> $2 [30 Bob]
+	// `age` >= ? AND `name` <> ? [30 Bob]
+}
+
+func Test_Where_Rejects_Unknown(t *testing.T) {
+
+	columns := map[string]string{"age": "age"}
+
+	bad := []types.Query{
+		{Key: `age" OR 1=1 --`, Operator: "EQ", Value: 1},
+		{Key: "age", Operator: "= 1 OR 1", Value: 1},
+	}
+
+	for _, q := range bad {
+		_, _, err := Where(Postgres, columns, []types.Query{q}, 0)
+		if !errors.Is(err, e.ErrBadRequest) {
+			fmt.Printf("%v: expected bad request, got: %v\n", q, err)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Where_Offset_And_Quoting(t *testing.T) {
+
+	columns := map[string]string{"name": `u.we"ird`}
+	queries := []types.Query{{Key: "name", Operator: "EQ", Value: "x"}}
+
+	where, args, err := Where(Postgres, columns, queries, 2)
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	expected := `"u"."we""ird" = $3`
+	if where != expected || len(args) != 1 {
+		fmt.Printf("expected %s, got %s\n", expected, where)
+		t.Fail()
+	}
+
+	where, _, _ = Where(SQLite, columns, nil, 0)
+	if where != "" {
+		fmt.Println("expected an empty fragment without queries, got:", where)
+		t.Fail()
+	}
+}
+
+func Test_Where_Extended_Operators(t *testing.T) {
+
+	columns := map[string]string{"status": "status", "age": "age", "name": "name"}
+
+	tests := []struct {
+		query types.Query
+		where string
+		args  string
+	}{
+		{types.Query{Key: "status", Operator: "IN", Value: []any{"a", "b"}}, `"status" IN ($2, $3)`, "[a b]"},
+		{types.Query{Key: "status", Operator: "NIN", Value: []any{"a"}}, `"status" NOT IN ($2)`, "[a]"},
+		{types.Query{Key: "age", Operator: "BETWEEN", Value: []any{1, 9}}, `"age" BETWEEN $2 AND $3`, "[1 9]"},
+		{types.Query{Key: "name", Operator: "CONTAINS", Value: "50%_off!"}, `"name" LIKE $2 ESCAPE '!'`, "[%50!%!_off!!%]"},
+		{types.Query{Key: "name", Operator: "IPREFIX", Value: "al"}, `LOWER("name") LIKE LOWER($2) ESCAPE '!'`, "[al%]"},
+		{types.Query{Key: "name", Operator: "SUFFIX", Value: "ol"}, `"name" LIKE $2 ESCAPE '!'`, "[%ol]"},
+		{types.Query{Key: "name", Operator: "ISNULL"}, `"name" IS NULL`, "[]"},
+		{types.Query{Key: "name", Operator: "NOTNULL"}, `"name" IS NOT NULL`, "[]"},
+		{types.Query{Key: "age", Operator: "GT", Value: types.Ref("status")}, `"age" > "status"`, "[]"},
+		{types.Query{Key: "age", Operator: "BETWEEN", Value: []any{1, types.Ref("status")}}, `"age" BETWEEN $2 AND "status"`, "[1]"},
+	}
+
+	for _, test := range tests {
+		where, args, err := Where(Postgres, columns, []types.Query{test.query}, 1)
+		if err != nil {
+			fmt.Println(test.query, err)
+			t.Fail()
+			continue
+		}
+		if where != test.where || fmt.Sprint(args) != test.args {
+			fmt.Printf("%v: expected %s %s, got %s %v\n", test.query, test.where, test.args, where, args)
+			t.Fail()
+		}
+	}
+
+	bad := []types.Query{
+		{Key: "status", Operator: "IN", Value: []any{}},
+		{Key: "age", Operator: "BETWEEN", Value: []any{1}},
+		{Key: "name", Operator: "CONTAINS", Value: 1},
+		{Key: "age", Operator: "EQ", Value: types.Ref("secret")},
+	}
+
+	for _, q := range bad {
+		_, _, err := Where(Postgres, columns, []types.Query{q}, 0)
+		if !errors.Is(err, e.ErrBadRequest) {
+			fmt.Printf("%v: expected bad request, got: %v\n", q, err)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Where_Case_Sensitive_Matches(t *testing.T) {
+
+	columns := map[string]string{"name": "name"}
+
+	tests := []struct {
+		dialect Dialect
+		query   types.Query
+		where   string
+		args    string
+	}{
+		{MySQL, types.Query{Key: "name", Operator: "CONTAINS", Value: "50%_off!"}, "CAST(`name` AS BINARY) LIKE CAST(? AS BINARY) ESCAPE '!'", "[%50!%!_off!!%]"},
+		{MySQL, types.Query{Key: "name", Operator: "IPREFIX", Value: "al"}, "LOWER(`name`) LIKE LOWER(?) ESCAPE '!'", "[al%]"},
+		{SQLite, types.Query{Key: "name", Operator: "PREFIX", Value: "Al*"}, `"name" GLOB ?`, "[Al[*]*]"},
+		{SQLite, types.Query{Key: "name", Operator: "SUFFIX", Value: "[?]"}, `"name" GLOB ?`, "[*[[][?]]]"},
+		{SQLite, types.Query{Key: "name", Operator: "CONTAINS", Value: "%o_"}, `"name" GLOB ?`, "[*%o_*]"},
+		{SQLite, types.Query{Key: "name", Operator: "ICONTAINS", Value: "o"}, `LOWER("name") LIKE LOWER(?) ESCAPE '!'`, "[%o%]"},
+	}
+
+	for _, test := range tests {
+		where, args, err := Where(test.dialect, columns, []types.Query{test.query}, 0)
+		if err != nil {
+			fmt.Println(test.query, err)
+			t.Fail()
+			continue
+		}
+		if where != test.where || fmt.Sprint(args) != test.args {
+			fmt.Printf("%v: expected %s %s, got %s %v\n", test.query, test.where, test.args, where, args)
+			t.Fail()
+		}
+	}
+}
+
+func Test_WhereExpression(t *testing.T) {
+
+	columns := map[string]string{"status": "status", "owner": "owner"}
+
+	x := types.And(
+		types.Or(
+			types.Leaf(types.Query{Key: "status", Operator: "EQ", Value: "open"}),
+			types.Leaf(types.Query{Key: "status", Operator: "EQ", Value: "pending"}),
+		),
+		types.Not(types.Leaf(types.Query{Key: "owner", Operator: "EQ", Value: "x"})),
+	)
+
+	where, args, err := WhereExpression(Postgres, columns, x, 1)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	expected := `(("status" = $2 OR "status" = $3) AND NOT ("owner" = $4))`
+	if where != expected || fmt.Sprint(args) != "[open pending x]" {
+		fmt.Printf("expected %s, got %s %v\n", expected, where, args)
+		t.Fail()
+	}
+
+	where, args, err = WhereExpression(Postgres, columns, types.Not(types.FromQueries(nil)), 0)
+	if err != nil || where != "NOT (1 = 1)" || len(args) != 0 {
+		fmt.Println("an AND without children should match everything, got:", where, args, err)
+		t.Fail()
+	}
+
+	bad := []types.Expression{
+		types.Or(),
+		{Operator: "XOR", Children: []types.Expression{x}},
+		types.Or(types.Leaf(types.Query{Key: "unknown", Operator: "EQ", Value: 1})),
+	}
+	for _, x := range bad {
+		_, _, err := WhereExpression(Postgres, columns, x, 0)
+		if !errors.Is(err, e.ErrBadRequest) {
+			fmt.Printf("%v: expected bad request, got: %v\n", x, err)
+			t.Fail()
+		}
+	}
+}
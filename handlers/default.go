@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	i "github.com/pergamenum/go-consensus-standards/interfaces"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+	t "github.com/pergamenum/go-consensus-standards/types"
+)
+
+// Handler exposes an interfaces.Service as REST endpoints:
+//
+//	POST   <prefix>       -> CreateWithID, answering with the id and its location, or Create when the service is not an interfaces.Creator
+//	GET    <prefix>       -> SearchPage, or Search when the service is not an interfaces.Pager, using ?q=<key>,<operator>,<value>&sort=<keys>&limit=<n>&cursor=<cursor>&filter=<expression>
+//	                         or another syntax for the queries, see HandlerConfig.Syntax
+//	GET    <prefix>/<id>  -> Read
+//	PATCH  <prefix>/<id>  -> Update
+//	DELETE <prefix>/<id>  -> Delete
+type Handler[M any] struct {
+	service  i.Service[M]
+	prefix   string
+	idKey    string
+	schema   *t.Schema
+	errorLog *log.Logger
+}
+
+type HandlerConfig[M any] struct {
+	Service i.Service[M]
+	// Prefix is the path the handler is mounted on, e.g. "/users".
+	Prefix string
+	// TagKey is the struct tag key used to validate search queries. Defaults to "json". Ignored when Schema is set.
+	TagKey string
+	// IDKey is the update key the path id is stored under before calling Service.Update. Defaults to "id".
+	IDKey string
+	// Operators are the operators allowed in search queries. Defaults to constants.ValidOperators.
+	// Ignored when Schema is set.
+	Operators map[string]bool
+	// MaxLimit caps the number of items in a page of search results. Defaults to constants.MaxSearchLimit.
+	// Ignored when Schema is set.
+	MaxLimit int
+	// Syntax reads search queries from the URL. Defaults to types.CommaSyntax. Ignored when Schema is set.
+	Syntax t.URLSyntax
+	// Schema parses and validates searches. It takes precedence over TagKey, Operators, MaxLimit and Syntax.
+	// Defaults to types.DefaultSchema of those: when it cannot be built, searches fail with an internal error.
+	Schema *t.Schema
+	// ErrorLog receives the causes of internal errors, which are hidden from clients. Defaults to log.Default().
+	ErrorLog *log.Logger
+}
+
+func NewHandler[M any](conf HandlerConfig[M]) *Handler[M] {
+
+	idKey := conf.IDKey
+	if idKey == "" {
+		idKey = "id"
+	}
+	schema := conf.Schema
+	if schema == nil {
+		schema = t.DefaultSchema[M](t.SchemaConfig{
+			TagKey:    conf.TagKey,
+			Operators: conf.Operators,
+			MaxLimit:  conf.MaxLimit,
+			Syntax:    conf.Syntax,
+		})
+	}
+
+	errorLog := conf.ErrorLog
+	if errorLog == nil {
+		errorLog = log.Default()
+	}
+
+	return &Handler[M]{
+		service:  conf.Service,
+		prefix:   strings.TrimSuffix(conf.Prefix, "/"),
+		idKey:    idKey,
+		schema:   schema,
+		errorLog: errorLog,
+	}
+}
+
+func (h *Handler[M]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	rest := strings.TrimPrefix(r.URL.Path, h.prefix)
+	if !strings.HasPrefix(r.URL.Path, h.prefix) || (rest != "" && rest[0] != '/') {
+		http.NotFound(w, r)
+		return
+	}
+	id := strings.Trim(rest, "/")
+
+	if id == "" {
+		switch r.Method {
+		case http.MethodPost:
+			h.create(w, r)
+		case http.MethodGet:
+			h.search(w, r)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+		return
+	}
+
+	if strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.read(w, r, id)
+	case http.MethodPatch:
+		h.update(w, r, id)
+	case http.MethodDelete:
+		h.delete(w, r, id)
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPatch, http.MethodDelete)
+	}
+}
+
+func (h *Handler[M]) create(w http.ResponseWriter, r *http.Request) {
+
+	var model M
+	err := json.NewDecoder(r.Body).Decode(&model)
+	if err != nil {
+		h.writeError(w, e.Wrap(err, e.ErrBadRequest))
+		return
+	}
+
+	creator, ok := h.service.(i.Creator[M])
+	if !ok {
+		err = h.service.Create(r.Context(), model)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	id, err := creator.CreateWithID(r.Context(), model)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", h.prefix+"/"+url.PathEscape(id))
+	writeJSON(w, http.StatusCreated, createdResponse{ID: id})
+}
+
+type createdResponse struct {
+	ID string `json:"id"`
+}
+
+func (h *Handler[M]) read(w http.ResponseWriter, r *http.Request, id string) {
+
+	model, err := h.service.Read(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model)
+}
+
+// update decodes the body into the model, and builds the update from the 'update' tags of the fields present in the body,
+// so that fields left out keep their values rather than being reset to zero.
+func (h *Handler[M]) update(w http.ResponseWriter, r *http.Request, id string) {
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.writeError(w, e.Wrap(err, e.ErrBadRequest))
+		return
+	}
+	var sent map[string]json.RawMessage
+	err = json.Unmarshal(body, &sent)
+	if err != nil {
+		h.writeError(w, e.Wrap(err, e.ErrBadRequest))
+		return
+	}
+	var model M
+	err = json.Unmarshal(body, &model)
+	if err != nil {
+		h.writeError(w, e.Wrap(err, e.ErrBadRequest))
+		return
+	}
+
+	update, err := t.NewUpdate(model)
+	if err != nil {
+		h.writeError(w, e.Wrap(err, e.ErrBadRequest))
+		return
+	}
+	for _, f := range reflection.TaggedFields(reflect.TypeOf(model), "update") {
+		if name := jsonName(f.Field); name == "" || !present(sent, name) {
+			delete(update, f.Tag)
+		}
+	}
+	update[h.idKey] = id
+
+	err = h.service.Update(r.Context(), update)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler[M]) delete(w http.ResponseWriter, r *http.Request, id string) {
+
+	err := h.service.Delete(r.Context(), id)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// search always answers with a single page, limited to at most the configured maximum.
+func (h *Handler[M]) search(w http.ResponseWriter, r *http.Request) {
+
+	queries, opts, err := h.schema.ParseURL(r.URL.Query())
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	var page t.Page[M]
+	if pager, ok := h.service.(i.Pager[M]); ok {
+		page, err = pager.SearchPage(r.Context(), queries, opts)
+	} else {
+		page, err = t.SearchPageOf(r.Context(), h.service.Search, queries, opts)
+	}
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []M{}
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+	// Fields lists the invalid queries of a search, one per query.
+	Fields []t.QueryError `json:"fields,omitempty"`
+}
+
+// writeError answers with the error, except for internal errors: their cause is logged, and clients only learn that one occurred.
+func (h *Handler[M]) writeError(w http.ResponseWriter, err error) {
+
+	status := StatusCode(err)
+	if status == http.StatusInternalServerError {
+		h.errorLog.Printf("(internal error: %s)", err)
+		writeJSON(w, status, errorResponse{Error: "(internal error)"})
+		return
+	}
+
+	body := errorResponse{Error: err.Error()}
+	var ve *t.ValidationError
+	if errors.As(err, &ve) {
+		body.Fields = ve.Errors
+	}
+
+	writeJSON(w, status, body)
+}
+
+// jsonName returns the key encoding/json decodes the field from, or "" when it ignores the field.
+func jsonName(f reflect.StructField) string {
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		return f.Name
+	}
+
+	return name
+}
+
+// present reports whether the object holds the key, matched without regard to case like encoding/json does.
+func present(object map[string]json.RawMessage, key string) bool {
+
+	if _, found := object[key]; found {
+		return true
+	}
+	for k := range object {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	cause := fmt.Sprintf("(method not allowed - allowed methods: '%s')", strings.Join(allowed, " "))
+	writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: cause})
+}
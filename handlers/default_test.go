@@ -0,0 +1,369 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	i "github.com/pergamenum/go-consensus-standards/interfaces"
+	"github.com/pergamenum/go-consensus-standards/types"
+)
+
+type user struct {
+	ID   string  `json:"id"`
+	Name *string `json:"name" update:"name"`
+	Age  int     `json:"age" update:"age"`
+}
+
+// stubService records its calls and answers with 'err' when set.
+type stubService struct {
+	err     error
+	created []user
+	updates []types.Update
+	queries []types.Query
+	options []types.SearchOptions
+}
+
+func (s *stubService) Create(_ context.Context, model user) error {
+	s.created = append(s.created, model)
+	return s.err
+}
+
+func (s *stubService) Read(_ context.Context, id string) (user, error) {
+	return user{ID: id}, s.err
+}
+
+func (s *stubService) Update(_ context.Context, update types.Update) error {
+	s.updates = append(s.updates, update)
+	return s.err
+}
+
+func (s *stubService) Delete(context.Context, string) error {
+	return s.err
+}
+
+func (s *stubService) Search(_ context.Context, queries []types.Query) ([]user, error) {
+	s.queries = append(s.queries, queries...)
+	return nil, s.err
+}
+
+func (s *stubService) SearchPage(_ context.Context, queries []types.Query, opts types.SearchOptions) (types.Page[user], error) {
+	s.queries = append(s.queries, queries...)
+	s.options = append(s.options, opts)
+	return types.Page[user]{}, s.err
+}
+
+// plainService hides the SearchPage method of the stub, like a service written before paging existed.
+type plainService struct {
+	i.Service[user]
+	found []user
+}
+
+func (p plainService) Search(context.Context, []types.Query) ([]user, error) {
+	return p.found, nil
+}
+
+func serve(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func Test_Handler_Routes(t *testing.T) {
+
+	s := &stubService{}
+	h := NewHandler[user](HandlerConfig[user]{Service: s, Prefix: "/users"})
+
+	tests := []struct {
+		method   string
+		target   string
+		body     string
+		expected int
+	}{
+		{http.MethodPost, "/users", `{"name":"Alice","age":30}`, http.StatusCreated},
+		{http.MethodPost, "/users", `{"name":`, http.StatusBadRequest},
+		{http.MethodGet, "/users/42", "", http.StatusOK},
+		{http.MethodPatch, "/users/42", `{"name":"Alicia"}`, http.StatusNoContent},
+		{http.MethodDelete, "/users/42", "", http.StatusNoContent},
+		{http.MethodGet, "/users?q=age,GE,30", "", http.StatusOK},
+		{http.MethodGet, "/users?q=age,GE,old", "", http.StatusBadRequest},
+		{http.MethodGet, "/users?q=age,XX,30", "", http.StatusBadRequest},
+		{http.MethodGet, "/users?q=age", "", http.StatusBadRequest},
+		{http.MethodGet, "/users?sort=-age,id&limit=1000", "", http.StatusOK},
+		{http.MethodGet, "/users?sort=nope", "", http.StatusBadRequest},
+		{http.MethodGet, "/users?limit=-1", "", http.StatusBadRequest},
+		{http.MethodGet, "/users?cursor=bm90LWEtY3Vyc29y", "", http.StatusBadRequest},
+		{http.MethodGet, "/users?other=1", "", http.StatusBadRequest},
+		{http.MethodGet, "/users?filter=or(age,LT,20,age,GT,60)", "", http.StatusOK},
+		{http.MethodGet, "/users?filter=or(age,LT,young)", "", http.StatusBadRequest},
+		{http.MethodPut, "/users/42", "", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/users/42/more", "", http.StatusNotFound},
+		{http.MethodGet, "/usersx", "", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		w := serve(h, test.method, test.target, test.body)
+		if w.Code != test.expected {
+			fmt.Printf("%s %s: expected %d, got %d: %s\n", test.method, test.target, test.expected, w.Code, w.Body)
+			t.Fail()
+		}
+	}
+
+	if len(s.updates) != 1 || s.updates[0]["id"] != "42" || s.updates[0]["name"] != "Alicia" {
+		fmt.Println("Update should carry the path id and the body fields, got:", s.updates)
+		t.Fail()
+	}
+	if _, found := s.updates[0]["age"]; len(s.updates) == 1 && found {
+		fmt.Println("Update should leave out the fields missing from the body, got:", s.updates[0])
+		t.Fail()
+	}
+	if len(s.queries) != 1 || s.queries[0].Value != 30 {
+		fmt.Println("Search should receive validated, typed queries, got:", s.queries)
+		t.Fail()
+	}
+	if len(s.options) != 3 || s.options[0].Limit != 100 || s.options[1].Limit != 100 || len(s.options[1].Sort) != 2 {
+		fmt.Println("Search should always be limited, got:", s.options)
+		t.Fail()
+	}
+	if len(s.options) == 3 && (s.options[2].Filter == nil || s.options[2].Filter.Children[1].Query.Value != 60) {
+		fmt.Println("Search should receive a validated filter, got:", s.options[2].Filter)
+		t.Fail()
+	}
+}
+
+func Test_Handler_Search_Envelope(t *testing.T) {
+
+	h := NewHandler[user](HandlerConfig[user]{Service: &stubService{}})
+
+	w := serve(h, http.MethodGet, "/", "")
+	expected := `{"items":[],"total":0}`
+	if strings.TrimSpace(w.Body.String()) != expected {
+		fmt.Printf("expected %s, got %s\n", expected, w.Body)
+		t.Fail()
+	}
+}
+
+func Test_Handler_Errors(t *testing.T) {
+
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{e.Wrap("missing", e.ErrNotFound), http.StatusNotFound},
+		{e.Wrap("duplicate", e.ErrConflict), http.StatusConflict},
+		{e.Wrap("invalid", e.ErrBadRequest), http.StatusBadRequest},
+		{e.Wrap("upstream", e.ErrBadGateway), http.StatusBadGateway},
+		{e.Wrap("broken", e.ErrCorrupt), http.StatusInternalServerError},
+		{e.Wrap("oops", e.ErrInternal), http.StatusInternalServerError},
+		{errors.New("unknown"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		h := NewHandler[user](HandlerConfig[user]{Service: &stubService{err: test.err}, ErrorLog: log.New(io.Discard, "", 0)})
+		w := serve(h, http.MethodGet, "/42", "")
+		if w.Code != test.expected {
+			fmt.Printf("%v: expected %d, got %d\n", test.err, test.expected, w.Code)
+			t.Fail()
+		}
+		if !strings.Contains(w.Body.String(), `"error"`) {
+			fmt.Println("expected an error body, got:", w.Body)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Handler_Internal_Errors(t *testing.T) {
+
+	var logged bytes.Buffer
+	h := NewHandler[user](HandlerConfig[user]{
+		Service:  &stubService{err: e.Wrap("(pq: password authentication failed)", e.ErrInternal)},
+		ErrorLog: log.New(&logged, "", 0),
+	})
+
+	w := serve(h, http.MethodGet, "/42", "")
+	if strings.Contains(w.Body.String(), "pq:") || !strings.Contains(w.Body.String(), "internal error") {
+		fmt.Println("internal errors should be hidden from clients, got:", w.Body)
+		t.Fail()
+	}
+	if !strings.Contains(logged.String(), "pq: password authentication failed") {
+		fmt.Println("internal errors should be logged, got:", logged.String())
+		t.Fail()
+	}
+}
+
+func Test_Handler_Search_Field_Errors(t *testing.T) {
+
+	h := NewHandler[user](HandlerConfig[user]{Service: &stubService{}})
+
+	w := serve(h, http.MethodGet, "/?q=age,EQ,1&q=age,GT,old&q=nope,EQ,1", "")
+	if w.Code != http.StatusBadRequest {
+		fmt.Println("expected 400, got:", w.Code)
+		t.FailNow()
+	}
+
+	var body struct {
+		Fields []types.QueryError `json:"fields"`
+	}
+	err := json.Unmarshal(w.Body.Bytes(), &body)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if len(body.Fields) != 2 || body.Fields[0].Index != 1 || body.Fields[1].Key != "nope" {
+		fmt.Println("expected one field error per invalid query, got:", w.Body)
+		t.Fail()
+	}
+}
+
+func Test_Handler_Search_Syntax(t *testing.T) {
+
+	s := &stubService{}
+	h := NewHandler[user](HandlerConfig[user]{Service: s, Syntax: types.ColonSyntax{}})
+
+	w := serve(h, http.MethodGet, "/?age=ge:30&limit=5", "")
+	if w.Code != http.StatusOK {
+		fmt.Println("expected 200, got:", w.Code, w.Body)
+		t.FailNow()
+	}
+	if len(s.queries) != 1 || s.queries[0].Operator != "GE" || s.queries[0].Value != 30 {
+		fmt.Println("expected the query in colon syntax to be decoded and validated, got:", s.queries)
+		t.Fail()
+	}
+	if len(s.options) != 1 || s.options[0].Limit != 5 {
+		fmt.Println("expected the search options to coexist with the queries, got:", s.options)
+		t.Fail()
+	}
+}
+
+func Test_Handler_Search_Without_Pager(t *testing.T) {
+
+	s := plainService{Service: &stubService{}, found: []user{{ID: "1"}, {ID: "2"}, {ID: "3"}}}
+	h := NewHandler[user](HandlerConfig[user]{Service: s})
+
+	w := serve(h, http.MethodGet, "/?limit=2", "")
+	var page types.Page[user]
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	if w.Code != http.StatusOK || len(page.Items) != 2 || page.Total != 3 || page.NextCursor == "" {
+		fmt.Println("expected the first of two pages, got:", w.Code, w.Body)
+		t.Fail()
+	}
+
+	w = serve(h, http.MethodGet, "/?limit=2&cursor="+page.NextCursor, "")
+	page = types.Page[user]{}
+	_ = json.Unmarshal(w.Body.Bytes(), &page)
+	if w.Code != http.StatusOK || len(page.Items) != 1 || page.Items[0].ID != "3" || page.NextCursor != "" {
+		fmt.Println("expected the last page, got:", w.Code, w.Body)
+		t.Fail()
+	}
+
+	w = serve(h, http.MethodGet, "/?sort=age", "")
+	if w.Code != http.StatusBadRequest {
+		fmt.Println("sorting should be rejected without a pager, got:", w.Code, w.Body)
+		t.Fail()
+	}
+}
+
+func Test_Handler_Schema(t *testing.T) {
+
+	schema, err := types.NewSchema[user](types.SchemaConfig{MaxLimit: 3, Aliases: map[string]string{"years": "age"}})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	s := &stubService{}
+	h := NewHandler[user](HandlerConfig[user]{Service: s, Schema: schema, MaxLimit: 50})
+
+	serve(h, http.MethodGet, "/", "")
+	if len(s.options) != 1 || s.options[0].Limit != 3 {
+		fmt.Println("the schema should take precedence over MaxLimit, got:", s.options)
+		t.Fail()
+	}
+
+	w := serve(h, http.MethodGet, "/?q=years,GE,old", "")
+	var body struct {
+		Fields []types.QueryError `json:"fields"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusBadRequest || len(body.Fields) != 1 || body.Fields[0].Key != "years" {
+		fmt.Println("field errors should carry the key the client sent, got:", w.Code, w.Body)
+		t.Fail()
+	}
+
+	type listing struct {
+		Limit int `json:"limit"`
+	}
+	var logged bytes.Buffer
+	l := NewHandler[listing](HandlerConfig[listing]{Syntax: types.ColonSyntax{}, ErrorLog: log.New(&logged, "", 0)})
+
+	w = serve(l, http.MethodGet, "/", "")
+	if w.Code != http.StatusInternalServerError || !strings.Contains(logged.String(), "limit") {
+		fmt.Println("searches should fail when the default schema cannot be built, got:", w.Code, logged.String())
+		t.Fail()
+	}
+}
+
+func Test_Handler_Update_Sent_Fields(t *testing.T) {
+
+	s := &stubService{}
+	h := NewHandler[user](HandlerConfig[user]{Service: s})
+
+	w := serve(h, http.MethodPatch, "/42", `{"Age":0}`)
+	if w.Code != http.StatusNoContent {
+		fmt.Println("expected 204, got:", w.Code, w.Body)
+		t.FailNow()
+	}
+	if age, found := s.updates[0]["age"]; !found || age != 0 || len(s.updates[0]) != 2 {
+		fmt.Println("Update should carry exactly the fields sent, zero or not, got:", s.updates[0])
+		t.Fail()
+	}
+
+	w = serve(h, http.MethodPatch, "/42", `["age"]`)
+	if w.Code != http.StatusBadRequest {
+		fmt.Println("Update should reject bodies that are not objects, got:", w.Code)
+		t.Fail()
+	}
+}
+
+// creatingService adds the CreateWithID method to the stub, answering with a fixed id.
+type creatingService struct {
+	*stubService
+}
+
+func (c creatingService) CreateWithID(ctx context.Context, model user) (string, error) {
+	return "a b", c.Create(ctx, model)
+}
+
+func Test_Handler_Create_ID(t *testing.T) {
+
+	s := &stubService{}
+	h := NewHandler[user](HandlerConfig[user]{Service: creatingService{s}, Prefix: "/users"})
+
+	w := serve(h, http.MethodPost, "/users", `{"name":"Alice"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "/users/a%20b" || !strings.Contains(w.Body.String(), `"id":"a b"`) {
+		fmt.Println("expected the created id and its location, got:", w.Code, w.Header(), w.Body)
+		t.Fail()
+	}
+
+	h = NewHandler[user](HandlerConfig[user]{Service: s, Prefix: "/users"})
+	w = serve(h, http.MethodPost, "/users", `{"name":"Alice"}`)
+	if w.Code != http.StatusCreated || w.Header().Get("Location") != "" || w.Body.Len() != 0 {
+		fmt.Println("services without CreateWithID should be answered without a body, got:", w.Code, w.Header(), w.Body)
+		t.Fail()
+	}
+	if len(s.created) != 2 {
+		fmt.Println("expected both models to be created, got:", s.created)
+		t.Fail()
+	}
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+)
+
+// StatusCode maps the ehandler sentinels to HTTP status codes.
+//
+//	Errors that wrap no known sentinel are treated as internal errors.
+func StatusCode(err error) int {
+
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, e.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, e.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, e.ErrBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, e.ErrBadGateway):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
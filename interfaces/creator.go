@@ -0,0 +1,13 @@
+package interfaces
+
+import (
+	"context"
+)
+
+// Creator is implemented by a Service that generates the ids of the models it creates, and reports them.
+//
+// It is kept apart from Service so that existing implementations need not change:
+// callers discover it with a type assertion, and fall back to Create when it is missing.
+type Creator[Model any] interface {
+	CreateWithID(ctx context.Context, model Model) (id string, err error)
+}
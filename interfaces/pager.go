@@ -0,0 +1,15 @@
+package interfaces
+
+import (
+	"context"
+
+	t "github.com/pergamenum/go-consensus-standards/types"
+)
+
+// Pager is implemented by a DAO, Repository or Service that can search a single page at a time, sorted and filtered.
+//
+// It is kept apart from those interfaces so that their existing implementations need not change:
+// callers discover it with a type assertion, and fall back to Search when it is missing.
+type Pager[T any] interface {
+	SearchPage(ctx context.Context, query []t.Query, opts t.SearchOptions) (t.Page[T], error)
+}
@@ -2,6 +2,7 @@ package reflection
 
 import (
 	"fmt"
+	"sync"
 	"testing"
 	"time"
 )
@@ -543,3 +544,118 @@ func Test_AutoMap_Equal_Struct_Types(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func Test_AutoMap_Concurrent_Plans(t *testing.T) {
+
+	resetPlans()
+	a := newAlpha(3)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f, err := AutoMap[Foo](a)
+			if err == nil && (f.S != a.S || f.N.N.S != a.N.N.S) {
+				err = fmt.Errorf("mapped values differ")
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			fmt.Println(err)
+			t.Fail()
+		}
+	}
+}
+
+func Test_AutoMap_Collections(t *testing.T) {
+
+	type AddressModel struct {
+		Street string `automap:"street"`
+	}
+
+	type AddressEntity struct {
+		Street *string `automap:"street"`
+	}
+
+	type Source struct {
+		List     []AddressModel           `automap:"list"`
+		Pointers []*AddressModel          `automap:"pointers"`
+		Array    [2]AddressModel          `automap:"array"`
+		Map      map[string]*AddressModel `automap:"map"`
+		Tags     []string                 `automap:"tags"`
+		Empty    []AddressModel           `automap:"empty"`
+	}
+
+	type Target struct {
+		List     []AddressEntity          `automap:"list"`
+		Pointers []*AddressEntity         `automap:"pointers"`
+		Array    [2]*AddressEntity        `automap:"array"`
+		Map      map[string]AddressEntity `automap:"map"`
+		Tags     []string                 `automap:"tags"`
+		Empty    []AddressEntity          `automap:"empty"`
+	}
+
+	source := Source{
+		List:     []AddressModel{{"a"}, {"b"}},
+		Pointers: []*AddressModel{{"c"}, nil},
+		Array:    [2]AddressModel{{"d"}, {"e"}},
+		Map:      map[string]*AddressModel{"home": {"f"}, "none": nil},
+		Tags:     []string{"x"},
+	}
+
+	target, err := AutoMap[Target](source)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	if len(target.List) != 2 || *target.List[1].Street != "b" {
+		fmt.Println("AutoMap failed to map a slice of structs.")
+		t.Fail()
+	}
+	if len(target.Pointers) != 2 || *target.Pointers[0].Street != "c" || target.Pointers[1] != nil {
+		fmt.Println("AutoMap failed to map a slice of pointers, keeping nil elements.")
+		t.Fail()
+	}
+	if *target.Array[0].Street != "d" || *target.Array[1].Street != "e" {
+		fmt.Println("AutoMap failed to map an array of structs.")
+		t.Fail()
+	}
+	if len(target.Map) != 2 || *target.Map["home"].Street != "f" || target.Map["none"].Street != nil {
+		fmt.Println("AutoMap failed to map a map of structs.")
+		t.Fail()
+	}
+	if len(target.Tags) != 1 || target.Tags[0] != "x" {
+		fmt.Println("AutoMap failed to copy a slice of identical types.")
+		t.Fail()
+	}
+	if target.Empty != nil {
+		fmt.Println("AutoMap should leave the target nil when the source is nil.")
+		t.Fail()
+	}
+
+	type BadTarget struct {
+		Array [3]AddressEntity `automap:"array"`
+	}
+	_, err = AutoMap[BadTarget](source)
+	if err == nil {
+		fmt.Println("AutoMap should not allow arrays of different lengths.")
+		t.Fail()
+	}
+
+	type BadKeys struct {
+		Map map[int]AddressEntity `automap:"map"`
+	}
+	_, err = AutoMap[BadKeys](source)
+	if err == nil {
+		fmt.Println("AutoMap should not allow maps with different key types.")
+		t.Fail()
+	}
+}
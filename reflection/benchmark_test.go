@@ -162,3 +162,39 @@ func Benchmark_Mapping(b *testing.B) {
 		}
 	})
 }
+
+// resetPlans empties the cache of mapping plans, forcing AutoMap to compile them again.
+func resetPlans() {
+	plans.Range(func(key, _ any) bool {
+		plans.Delete(key)
+		return true
+	})
+}
+
+func Benchmark_Mapping_Plans(b *testing.B) {
+
+	rand.Seed(time.Now().UnixNano())
+
+	for _, d := range []int{1, 10} {
+
+		a := newAlpha(d)
+
+		s := fmt.Sprintf("AutoMap_Uncached_D[%v]", d)
+		b.Run(s, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				resetPlans()
+				_, _ = AutoMap[Foo](a)
+			}
+		})
+
+		s = fmt.Sprintf("AutoMap_Cached_D[%v]", d)
+		b.Run(s, func(b *testing.B) {
+			resetPlans()
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = AutoMap[Foo](a)
+			}
+		})
+	}
+}
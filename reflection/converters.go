@@ -0,0 +1,96 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+type converter func(reflect.Value) (reflect.Value, error)
+
+// Converters holds conversion functions between pairs of types, consulted by AutoMap before its kind check.
+//
+// The zero value is ready to use, and safe for concurrent use.
+type Converters struct {
+	mu sync.Mutex
+	// m is replaced, never altered, so that lookups need no lock.
+	m atomic.Pointer[map[[2]reflect.Type]converter]
+}
+
+// globalConverters are consulted by every AutoMap call, after any per-call converters.
+var globalConverters Converters
+
+// AddConverter registers a conversion from S to T in the given converters, replacing any previous one.
+func AddConverter[S any, T any](c *Converters, f func(S) (T, error)) {
+
+	key := [2]reflect.Type{reflect.TypeOf((*S)(nil)).Elem(), reflect.TypeOf((*T)(nil)).Elem()}
+	conv := func(v reflect.Value) (reflect.Value, error) {
+		result, err := f(v.Interface().(S))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&result).Elem(), nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m := map[[2]reflect.Type]converter{}
+	if old := c.m.Load(); old != nil {
+		for k, v := range *old {
+			m[k] = v
+		}
+	}
+	m[key] = conv
+	c.m.Store(&m)
+}
+
+// RegisterConverter registers a conversion from S to T for every AutoMap call.
+//
+// For example, to store time.Time as unix milliseconds:
+//
+//	RegisterConverter(func(t time.Time) (int64, error) { return t.UnixMilli(), nil })
+func RegisterConverter[S any, T any](f func(S) (T, error)) {
+	AddConverter(&globalConverters, f)
+}
+
+func (c *Converters) lookup(s, t reflect.Type) (converter, bool) {
+
+	if c == nil {
+		return nil, false
+	}
+	m := c.m.Load()
+	if m == nil {
+		return nil, false
+	}
+	conv, found := (*m)[[2]reflect.Type{s, t}]
+
+	return conv, found
+}
+
+// convert applies the per-call converter for the value's type pair, falling back to the global one.
+//
+//	Reports false when there is no converter for the pair.
+func convert(local *Converters, source, target reflect.Value) (bool, error) {
+
+	conv, found := local.lookup(source.Type(), target.Type())
+	if !found {
+		conv, found = globalConverters.lookup(source.Type(), target.Type())
+	}
+	if !found {
+		return false, nil
+	}
+
+	result, err := conv(source)
+	if err != nil {
+		cause := &mappingError{
+			reason: "conversion failed",
+			detail: fmt.Sprintf("source: '%s', target: '%s', info: %s", source.Type(), target.Type(), err.Error()),
+		}
+		return true, cause
+	}
+	target.Set(result)
+
+	return true, nil
+}
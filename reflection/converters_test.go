@@ -0,0 +1,78 @@
+package reflection
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type uuid [16]byte
+
+func ExampleRegisterConverter() {
+
+	RegisterConverter(func(u uuid) (string, error) {
+		return hex.EncodeToString(u[:]), nil
+	})
+
+	type Model struct {
+		ID uuid `automap:"id"`
+	}
+	type Entity struct {
+		ID string `automap:"id"`
+	}
+
+	e, err := AutoMap[Entity](Model{ID: uuid{0xca, 0xfe}})
+	if err != nil {
+		// Handle err
+	}
+	fmt.Println(e.ID)
+
+	// Output:
+	// cafe0000000000000000000000000000
+}
+
+func Test_AutoMapWith_Converters(t *testing.T) {
+
+	type Model struct {
+		Created time.Time  `automap:"created"`
+		Updated *time.Time `automap:"updated"`
+		Deleted *time.Time `automap:"deleted"`
+	}
+
+	type Entity struct {
+		Created int64  `automap:"created"`
+		Updated *int64 `automap:"updated"`
+		Deleted *int64 `automap:"deleted"`
+	}
+
+	now := time.UnixMilli(1666000000000)
+	model := Model{Created: now, Updated: &now}
+
+	_, err := AutoMap[Entity](model)
+	if err == nil {
+		fmt.Println("AutoMap should not convert without a registered converter.")
+		t.Fail()
+	}
+
+	var c Converters
+	AddConverter(&c, func(t time.Time) (int64, error) { return t.UnixMilli(), nil })
+
+	entity, err := AutoMapWith[Entity](model, Options{Converters: &c})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if entity.Created != now.UnixMilli() || *entity.Updated != now.UnixMilli() || entity.Deleted != nil {
+		fmt.Printf("AutoMapWith failed to convert: %+v\n", entity)
+		t.Fail()
+	}
+
+	AddConverter(&c, func(int64) (time.Time, error) { return time.Time{}, errors.New("refused") })
+	_, err = AutoMapWith[Model](entity, Options{Converters: &c})
+	if err == nil {
+		fmt.Println("AutoMapWith should report failing converters.")
+		t.Fail()
+	}
+}
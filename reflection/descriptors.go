@@ -0,0 +1,246 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	c "github.com/pergamenum/go-consensus-standards/constants"
+)
+
+// FieldType describes the type of a struct field, in the terms queries are validated and converted in.
+//
+// Pointers are peeled, so '*int' is described as an 'int' with Pointer set,
+// and slices and arrays are described by their element type in Elem.
+type FieldType struct {
+	// Type is the peeled type, nil when the descriptor was built from a type name alone.
+	Type reflect.Type
+	// Name is the name of the peeled type, such as 'int', 'Status' or 'Time'. Empty for unnamed types.
+	Name string
+	// Kind is the kind of the peeled type.
+	Kind reflect.Kind
+	// Base is the predeclared type values are parsed as, such as 'string' for 'type Status string'.
+	// Times are 'time'. Empty when values of the type cannot be parsed.
+	Base string
+	// Named reports whether the type is defined on top of its base, such as 'type Status string'.
+	Named bool
+	// Pointer reports whether the field is a pointer.
+	Pointer bool
+	// Elem describes the elements of slices and arrays, nil otherwise.
+	Elem *FieldType
+	// Enum lists the allowed values, in their string form, when restricted by RegisterEnum or an 'enum' tag.
+	Enum []string
+	// Operators lists the operators permitted on the field, when restricted by a 'query' tag.
+	Operators []string
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// basics are the predeclared types, by kind, that queries know how to parse.
+var basics = map[reflect.Kind]string{
+	reflect.Bool:       "bool",
+	reflect.String:     "string",
+	reflect.Int:        "int",
+	reflect.Int8:       "int8",
+	reflect.Int16:      "int16",
+	reflect.Int32:      "int32",
+	reflect.Int64:      "int64",
+	reflect.Uint:       "uint",
+	reflect.Uint8:      "uint8",
+	reflect.Uint16:     "uint16",
+	reflect.Uint32:     "uint32",
+	reflect.Uint64:     "uint64",
+	reflect.Float32:    "float32",
+	reflect.Float64:    "float64",
+	reflect.Complex64:  "complex64",
+	reflect.Complex128: "complex128",
+}
+
+// DescribeType returns the descriptor of the type.
+func DescribeType(t reflect.Type) FieldType {
+
+	var ft FieldType
+	if t == nil {
+		return ft
+	}
+
+	for t.Kind() == reflect.Pointer {
+		ft.Pointer = true
+		t = t.Elem()
+	}
+
+	ft.Type = t
+	ft.Name = t.Name()
+	ft.Kind = t.Kind()
+
+	switch {
+	case t == timeType:
+		ft.Base = "time"
+	case ft.Kind == reflect.Slice || ft.Kind == reflect.Array:
+		elem := DescribeType(t.Elem())
+		ft.Elem = &elem
+	default:
+		ft.Base = basics[ft.Kind]
+		ft.Named = ft.Base != "" && ft.Name != "" && ft.Name != ft.Base
+		ft.Enum = enumOf(t)
+	}
+
+	return ft
+}
+
+// TypeFromName returns the descriptor of a type known only by its name, as recorded by MapTagToType.
+//
+//	The descriptor has no Type, so values are converted to the predeclared type with that name.
+func TypeFromName(name string) FieldType {
+
+	ft := FieldType{Name: name}
+
+	lower := strings.ToLower(name)
+	switch lower {
+	case "time":
+		ft.Kind = reflect.Struct
+		ft.Base = lower
+	case "byte":
+		ft.Kind = reflect.Uint8
+		ft.Base = "uint8"
+	case "rune":
+		ft.Kind = reflect.Int32
+		ft.Base = "int32"
+	default:
+		for k, b := range basics {
+			if b == lower {
+				ft.Kind = k
+				ft.Base = b
+			}
+		}
+	}
+
+	return ft
+}
+
+// Scalar returns the descriptor single query values are converted to: the element type of slices and arrays,
+// and the type itself otherwise.
+func (ft FieldType) Scalar() FieldType {
+
+	if ft.Elem != nil {
+		return *ft.Elem
+	}
+
+	return ft
+}
+
+// String returns the type in Go syntax, such as '*int' or '[]Status'.
+func (ft FieldType) String() string {
+
+	var sb strings.Builder
+	if ft.Pointer {
+		sb.WriteString("*")
+	}
+	switch {
+	case ft.Elem != nil && ft.Name == "":
+		sb.WriteString("[]")
+		sb.WriteString(ft.Elem.String())
+	case ft.Name != "":
+		sb.WriteString(ft.Name)
+	case ft.Type != nil:
+		sb.WriteString(ft.Type.String())
+	}
+
+	return sb.String()
+}
+
+// MapTagToFieldType is like MapTagToType, but describes each field with a FieldType.
+//
+// An 'enum' tag on a field, such as `enum:"open,closed"`, takes precedence over the values registered for its type.
+// On slices and arrays, it restricts the elements.
+//
+// The 'ops' option of a 'query' tag on a field, such as `query:"name,ops=EQ|NE"`, restricts its operators.
+// Unknown operators in it are left out, see DescribeFields to report them.
+func MapTagToFieldType(tagKey string, inputStruct any) map[string]FieldType {
+
+	m, _ := DescribeFields(tagKey, inputStruct)
+
+	return m
+}
+
+// DescribeFields is like MapTagToFieldType, but fails on the first unknown operator in the 'ops' option of a 'query' tag.
+func DescribeFields(tagKey string, inputStruct any) (map[string]FieldType, error) {
+
+	t := reflect.TypeOf(inputStruct)
+	if t == nil {
+		return nil, nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	var first error
+	m := map[string]FieldType{}
+	for _, p := range TaggedPaths(t, tagKey, MaxPathDepth) {
+		ft := DescribeType(p.Field().Type)
+		if enum := enumTag(p.Field()); enum != nil {
+			if ft.Elem != nil {
+				elem := *ft.Elem
+				elem.Enum = enum
+				ft.Elem = &elem
+			} else {
+				ft.Enum = enum
+			}
+		}
+		var err error
+		ft.Operators, err = operatorsTag(p.Field())
+		if err != nil && first == nil {
+			first = fmt.Errorf("(field '%s': %w)", p.Key, err)
+		}
+		m[p.Key] = ft
+	}
+
+	return m, first
+}
+
+// operatorsTag returns the operators listed in the 'ops' option of the field's 'query' tag, separated by '|'.
+//
+//	Unknown operators are left out, and reported by the error.
+func operatorsTag(f reflect.StructField) ([]string, error) {
+
+	for _, option := range strings.Split(f.Tag.Get("query"), ",") {
+		option = strings.TrimSpace(option)
+		if !strings.HasPrefix(option, "ops=") {
+			continue
+		}
+		ops := strings.TrimPrefix(option, "ops=")
+		var operators []string
+		var err error
+		for _, op := range strings.Split(ops, "|") {
+			op = strings.TrimSpace(op)
+			switch {
+			case op == "":
+				continue
+			case c.ValidOperators[op]:
+				operators = append(operators, op)
+			case err == nil:
+				err = fmt.Errorf("(invalid operator '%s' in query tag - valid operators: '%s')", op, validOperators())
+			}
+			// A field listing only unknown operators permits none, rather than every operator.
+			if operators == nil {
+				operators = []string{}
+			}
+		}
+		return operators, err
+	}
+
+	return nil, nil
+}
+
+func validOperators() string {
+
+	ops := make([]string, 0, len(c.ValidOperators))
+	for op := range c.ValidOperators {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	return strings.Join(ops, " ")
+}
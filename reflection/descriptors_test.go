@@ -0,0 +1,94 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Status string
+
+func ExampleMapTagToFieldType() {
+
+	type Ticket struct {
+		Status   Status    `json:"status"`
+		Assignee *int      `json:"assignee"`
+		Labels   []string  `json:"labels"`
+		Due      time.Time `json:"due"`
+	}
+
+	fields := MapTagToFieldType("json", Ticket{})
+
+	for _, k := range []string{"status", "assignee", "labels", "due"} {
+		ft := fields[k]
+		fmt.Printf("%s: %s base '%s' named %v\n", k, ft, ft.Scalar().Base, ft.Named)
+	}
+
+	// Output:
+	// status: Status base 'string' named true
+	// assignee: *int base 'int' named false
+	// labels: []string base 'string' named false
+	// due: Time base 'time' named false
+}
+
+func Test_DescribeType(t *testing.T) {
+
+	tests := []struct {
+		input any
+		want  FieldType
+	}{
+		{input: 1, want: FieldType{Name: "int", Kind: reflect.Int, Base: "int"}},
+		{input: Status(""), want: FieldType{Name: "Status", Kind: reflect.String, Base: "string", Named: true}},
+		{input: new(float64), want: FieldType{Name: "float64", Kind: reflect.Float64, Base: "float64", Pointer: true}},
+		{input: map[string]int{}, want: FieldType{Kind: reflect.Map}},
+	}
+
+	for _, tc := range tests {
+		got := DescribeType(reflect.TypeOf(tc.input))
+		got.Type = nil
+		if !reflect.DeepEqual(got, tc.want) {
+			fmt.Printf("DescribeType(%T) = %+v, want %+v\n", tc.input, got, tc.want)
+			t.Fail()
+		}
+	}
+
+	got := DescribeType(reflect.TypeOf([]*Status{}))
+	if got.Kind != reflect.Slice || got.Elem == nil || !got.Elem.Pointer || got.Elem.Type != reflect.TypeOf(Status("")) {
+		fmt.Printf("DescribeType failed to describe the elements of a slice, got: %+v\n", got)
+		t.Fail()
+	}
+
+	if ft := TypeFromName("Time"); ft.Base != "time" || ft.Type != nil {
+		fmt.Printf("TypeFromName failed to describe a time, got: %+v\n", ft)
+		t.Fail()
+	}
+	if ft := TypeFromName("Status"); ft.Base != "" {
+		fmt.Printf("TypeFromName should not guess the base of unknown names, got: %+v\n", ft)
+		t.Fail()
+	}
+}
+
+func Test_DescribeFields_Operators(t *testing.T) {
+
+	type Ticket struct {
+		ID    string `json:"id" query:"id,ops=EQ|IN"`
+		Age   int    `json:"age" query:"age,ops=EQ|GTE"`
+		Owner string `json:"owner" query:"owner,ops=GTE"`
+	}
+
+	fields, err := DescribeFields("json", Ticket{})
+	if err == nil || !strings.Contains(err.Error(), "'age'") || !strings.Contains(err.Error(), "'GTE'") {
+		fmt.Println("DescribeFields should report the unknown operator and its field, got:", err)
+		t.Fail()
+	}
+	if !reflect.DeepEqual(fields["id"].Operators, []string{"EQ", "IN"}) || !reflect.DeepEqual(fields["age"].Operators, []string{"EQ"}) {
+		fmt.Println("DescribeFields should keep the known operators, got:", fields)
+		t.Fail()
+	}
+	if ops := MapTagToFieldType("json", Ticket{})["owner"].Operators; ops == nil || len(ops) != 0 {
+		fmt.Println("A field listing only unknown operators should permit none, got:", ops)
+		t.Fail()
+	}
+}
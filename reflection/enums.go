@@ -0,0 +1,59 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// enums holds the allowed values of registered types, as map[reflect.Type][]string.
+var enums sync.Map
+
+// RegisterEnum registers the allowed values of a type, replacing any previous ones.
+//
+// Descriptors of T, and of fields of type T, then list the values in FieldType.Enum:
+//
+//	type Status string
+//	RegisterEnum(Status("open"), Status("closed"))
+//
+// Register enums before describing the types that use them, typically in an init function.
+func RegisterEnum[T any](values ...T) {
+
+	key := reflect.TypeOf((*T)(nil)).Elem()
+	enum := make([]string, len(values))
+	for i := range values {
+		enum[i] = fmt.Sprint(values[i])
+	}
+
+	enums.Store(key, enum)
+}
+
+// enumOf returns the allowed values registered for the type, if any.
+func enumOf(t reflect.Type) []string {
+
+	enum, found := enums.Load(t)
+	if !found {
+		return nil
+	}
+
+	return enum.([]string)
+}
+
+// enumTag returns the allowed values listed in the field's 'enum' tag, such as `enum:"open,closed"`.
+func enumTag(f reflect.StructField) []string {
+
+	tag, found := f.Tag.Lookup("enum")
+	if !found {
+		return nil
+	}
+
+	var enum []string
+	for _, v := range strings.Split(tag, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			enum = append(enum, v)
+		}
+	}
+
+	return enum
+}
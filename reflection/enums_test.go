@@ -0,0 +1,38 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type Priority string
+
+func ExampleRegisterEnum() {
+
+	RegisterEnum(Priority("low"), Priority("high"))
+
+	type Ticket struct {
+		Priority Priority `json:"priority"`
+		State    string   `json:"state" enum:"open,closed"`
+	}
+
+	fields := MapTagToFieldType("json", Ticket{})
+	fmt.Println(fields["priority"].Enum, fields["state"].Enum)
+
+	// Output:
+	// [low high] [open closed]
+}
+
+func Test_Enum_Tag_Elements(t *testing.T) {
+
+	type Ticket struct {
+		Labels []string `json:"labels" enum:"bug, ui"`
+	}
+
+	ft := MapTagToFieldType("json", Ticket{})["labels"]
+	if ft.Enum != nil || ft.Elem == nil || !reflect.DeepEqual(ft.Elem.Enum, []string{"bug", "ui"}) {
+		fmt.Printf("The enum tag of a slice should restrict its elements, got: %+v\n", ft)
+		t.Fail()
+	}
+}
@@ -0,0 +1,283 @@
+package reflection
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// TaggedField is a struct field marked with a given tag key, possibly promoted from an embedded struct.
+type TaggedField struct {
+	Tag string
+	// Index is the path to the field, for use with reflect.Value.FieldByIndex.
+	Index []int
+	Field reflect.StructField
+}
+
+// TaggedFields returns the fields of the struct type marked with the tag key, in field order.
+//
+// Fields of embedded structs without a tag of their own are promoted, following the rules of encoding/json:
+//
+//	A field at a shallower depth hides fields with the same tag at deeper depths.
+//	Fields with the same tag at the same depth are ambiguous, and are all left out.
+//
+// Returns nil when the type is not a struct, or a pointer to one.
+func TaggedFields(t reflect.Type, tagKey string) []TaggedField {
+
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var candidates []TaggedField
+	collectTagged(t, tagKey, nil, map[reflect.Type]bool{}, &candidates)
+
+	// Group the candidates by tag, and keep the single shallowest one of each.
+	byTag := map[string][]TaggedField{}
+	for _, c := range candidates {
+		byTag[c.Tag] = append(byTag[c.Tag], c)
+	}
+
+	var fields []TaggedField
+	for _, cs := range byTag {
+		shallowest := cs[0]
+		ambiguous := false
+		for _, c := range cs[1:] {
+			switch {
+			case len(c.Index) < len(shallowest.Index):
+				shallowest = c
+				ambiguous = false
+			case len(c.Index) == len(shallowest.Index):
+				ambiguous = true
+			}
+		}
+		if !ambiguous {
+			fields = append(fields, shallowest)
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		return lessIndex(fields[i].Index, fields[j].Index)
+	})
+
+	return fields
+}
+
+func collectTagged(t reflect.Type, tagKey string, prefix []int, visited map[reflect.Type]bool, out *[]TaggedField) {
+
+	// Guards against embedding cycles, such as 'type A struct { *A }'.
+	if visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for i := 0; i < t.NumField(); i++ {
+
+		f := t.Field(i)
+		index := append(append([]int{}, prefix...), i)
+
+		full := f.Tag.Get(tagKey)
+		split := strings.Split(full, ",")
+		tag := strings.TrimSpace(split[0])
+		// Typically used to show that a field is supposed to be ignored.
+		// For example: Age int `json:"-"`
+		if tag == "-" {
+			continue
+		}
+
+		if tag == "" {
+			if !f.Anonymous {
+				continue
+			}
+			et := f.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				collectTagged(et, tagKey, index, visited, out)
+			}
+			continue
+		}
+
+		*out = append(*out, TaggedField{Tag: tag, Index: index, Field: f})
+	}
+}
+
+func lessIndex(a, b []int) bool {
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+// FieldByIndex is like reflect.Value.FieldByIndex, but reports false instead of panicking on nil embedded pointers.
+func FieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+
+	f, err := v.FieldByIndexErr(index)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+
+	return f, true
+}
+
+// FieldByIndexAlloc is like reflect.Value.FieldByIndex, but allocates nil embedded pointers along the way.
+//
+//	The value must be settable. Reports false when a nil embedded pointer cannot be allocated, as it is unexported.
+func FieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	return fieldByIndexAlloc(v, index, false)
+}
+
+// fieldByIndexAlloc is FieldByIndexAlloc, also replacing the embedded pointers along the way by copies when 'copied' is set,
+// so that what they pointed to is never written to.
+func fieldByIndexAlloc(v reflect.Value, index []int, copied bool) (reflect.Value, bool) {
+
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() || copied {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(copyOf(v))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// copyOf returns a new pointer to a copy of what the pointer points to, or to a zero value when it is nil.
+func copyOf(ptr reflect.Value) reflect.Value {
+
+	c := reflect.New(ptr.Type().Elem())
+	if !ptr.IsNil() {
+		c.Elem().Set(ptr.Elem())
+	}
+
+	return c
+}
+
+// PathSeparator joins the tags of nested struct fields into a single key, such as 'address.city'.
+const PathSeparator = "."
+
+// MaxPathDepth is the maximum number of tags joined into a single key by MapTagToType.
+const MaxPathDepth = 4
+
+// TaggedPath is a field reached by following a tagged field at every level of nested structs.
+type TaggedPath struct {
+	// Key is the tags along the path, joined by PathSeparator.
+	Key string
+	// Fields holds the tagged field at every level, from the outermost struct inwards.
+	Fields []TaggedField
+}
+
+// Field returns the innermost field of the path.
+func (p TaggedPath) Field() reflect.StructField {
+	return p.Fields[len(p.Fields)-1].Field
+}
+
+// TaggedPaths returns every tagged field of the struct type and, recursively, of its nested structs, up to 'maxDepth' levels.
+//
+// Nested structs may be reached through pointers. A struct type is not expanded again within its own path,
+// so self-referential types such as 'type Node struct { Next *Node }' stop after one level.
+func TaggedPaths(t reflect.Type, tagKey string, maxDepth int) []TaggedPath {
+
+	var paths []TaggedPath
+	collectPaths(t, tagKey, maxDepth, TaggedPath{}, map[reflect.Type]bool{}, &paths)
+
+	return paths
+}
+
+func collectPaths(t reflect.Type, tagKey string, maxDepth int, parent TaggedPath, visited map[reflect.Type]bool, out *[]TaggedPath) {
+
+	t = peelType(t)
+	if t == nil || t.Kind() != reflect.Struct || len(parent.Fields) >= maxDepth || visited[t] {
+		return
+	}
+	visited[t] = true
+	defer delete(visited, t)
+
+	for _, f := range TaggedFields(t, tagKey) {
+
+		path := TaggedPath{Key: f.Tag, Fields: append(append([]TaggedField{}, parent.Fields...), f)}
+		if parent.Key != "" {
+			path.Key = parent.Key + PathSeparator + f.Tag
+		}
+		*out = append(*out, path)
+
+		collectPaths(f.Field.Type, tagKey, maxDepth, path, visited, out)
+	}
+}
+
+// ResolvePath returns the path of the struct type's field identified by the key, either a tag or dotted tags.
+func ResolvePath(t reflect.Type, tagKey, key string) (TaggedPath, bool) {
+
+	path := TaggedPath{Key: key}
+	for _, segment := range strings.Split(key, PathSeparator) {
+
+		t = peelType(t)
+		if t == nil || t.Kind() != reflect.Struct || len(path.Fields) >= MaxPathDepth {
+			return TaggedPath{}, false
+		}
+
+		found := false
+		for _, f := range TaggedFields(t, tagKey) {
+			if f.Tag == segment {
+				path.Fields = append(path.Fields, f)
+				t = f.Field.Type
+				found = true
+				break
+			}
+		}
+		if !found {
+			return TaggedPath{}, false
+		}
+	}
+
+	return path, true
+}
+
+// Value returns the field of the struct 'v' at the end of the path.
+//
+//	Reports false when a nil pointer, embedded or nested, lies along the path.
+func (p TaggedPath) Value(v reflect.Value) (reflect.Value, bool) {
+
+	for i, f := range p.Fields {
+		if i > 0 {
+			for v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					return reflect.Value{}, false
+				}
+				v = v.Elem()
+			}
+		}
+		var ok bool
+		v, ok = FieldByIndex(v, f.Index)
+		if !ok {
+			return reflect.Value{}, false
+		}
+	}
+
+	return v, true
+}
+
+func peelType(t reflect.Type) reflect.Type {
+
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
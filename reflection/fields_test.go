@@ -0,0 +1,192 @@
+package reflection
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type AuditFields struct {
+	Created time.Time `automap:"created" json:"created"`
+	Updated time.Time `automap:"updated" json:"updated"`
+}
+
+type Ownership struct {
+	Owner   string `automap:"owner" json:"owner"`
+	Updated string `automap:"updated" json:"updated"`
+}
+
+func ExampleTaggedFields() {
+
+	type Document struct {
+		ID string `json:"id"`
+		AuditFields
+	}
+
+	for _, f := range TaggedFields(reflect.TypeOf(Document{}), "json") {
+		fmt.Println(f.Tag, f.Index)
+	}
+
+	// Output:
+	// id [0]
+	// created [1 0]
+	// updated [1 1]
+}
+
+func Test_TaggedFields_Conflicts(t *testing.T) {
+
+	type Shallow struct {
+		AuditFields
+		Updated string `json:"updated"`
+	}
+	type Ambiguous struct {
+		AuditFields
+		*Ownership
+	}
+	type Cycle struct {
+		Name string `json:"name"`
+		*Cycle
+	}
+
+	tags := func(fs []TaggedField) map[string][]int {
+		m := map[string][]int{}
+		for _, f := range fs {
+			m[f.Tag] = f.Index
+		}
+		return m
+	}
+
+	shallow := tags(TaggedFields(reflect.TypeOf(Shallow{}), "json"))
+	if !reflect.DeepEqual(shallow["updated"], []int{1}) {
+		fmt.Println("A shallower field should hide promoted fields with the same tag, got:", shallow["updated"])
+		t.Fail()
+	}
+
+	ambiguous := tags(TaggedFields(reflect.TypeOf(Ambiguous{}), "json"))
+	if _, found := ambiguous["updated"]; found {
+		fmt.Println("Fields with the same tag at the same depth should be left out.")
+		t.Fail()
+	}
+	if len(ambiguous) != 2 {
+		fmt.Println("Unambiguous promoted fields should be kept, got:", ambiguous)
+		t.Fail()
+	}
+
+	cycle := tags(TaggedFields(reflect.TypeOf(Cycle{}), "json"))
+	if len(cycle) != 1 {
+		fmt.Println("Embedding cycles should be followed only once, got:", cycle)
+		t.Fail()
+	}
+}
+
+func Test_AutoMap_Embedded(t *testing.T) {
+
+	type Model struct {
+		Name    string    `automap:"name"`
+		Created time.Time `automap:"created"`
+		Owner   string    `automap:"owner"`
+	}
+	type Entity struct {
+		Name string `automap:"name"`
+		AuditFields
+		*Ownership
+	}
+
+	now := time.Now()
+	entity, err := AutoMap[Entity](Model{Name: "Jeff", Created: now, Owner: "Geoff"})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if entity.Created != now || entity.Ownership == nil || entity.Owner != "Geoff" {
+		fmt.Println("AutoMap failed to map into promoted fields, got:", entity)
+		t.Fail()
+	}
+
+	model, err := AutoMap[Model](Entity{Name: "Jeff", AuditFields: AuditFields{Created: now}})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if model.Created != now || model.Owner != "" {
+		fmt.Println("AutoMap failed to map from promoted fields through a nil embedded pointer, got:", model)
+		t.Fail()
+	}
+
+	m := MapTagToType("automap", Entity{})
+	if m["created"] != "Time" || m["owner"] != "string" {
+		fmt.Println("MapTagToType failed to include promoted fields, got:", m)
+		t.Fail()
+	}
+}
+
+func ExampleMapTagToType_nested() {
+
+	type Address struct {
+		City string `json:"city"`
+		Zip  int    `json:"zip"`
+	}
+	type User struct {
+		Name    string   `json:"name"`
+		Address *Address `json:"address"`
+	}
+
+	m := MapTagToType("json", User{})
+
+	fmt.Println(m["name"], m["address.city"], m["address.zip"])
+
+	// Output:
+	// string string int
+}
+
+func Test_TaggedPaths_Limits(t *testing.T) {
+
+	keys := func(ps []TaggedPath) []string {
+		var ks []string
+		for _, p := range ps {
+			ks = append(ks, p.Key)
+		}
+		return ks
+	}
+
+	// Alpha refers to itself through 'n', which must not be expanded again.
+	got := keys(TaggedPaths(reflect.TypeOf(Alpha{}), "automap", MaxPathDepth))
+	want := []string{"s", "i", "b", "t", "n"}
+	if !reflect.DeepEqual(got, want) {
+		fmt.Println("TaggedPaths failed to stop at a self-referential field, got:", got)
+		t.Fail()
+	}
+
+	type C struct {
+		V int `json:"v"`
+	}
+	type B struct {
+		C C `json:"c"`
+	}
+	type A struct {
+		B B `json:"b"`
+	}
+	got = keys(TaggedPaths(reflect.TypeOf(A{}), "json", 2))
+	want = []string{"b", "b.c"}
+	if !reflect.DeepEqual(got, want) {
+		fmt.Println("TaggedPaths failed to respect the depth limit, got:", got)
+		t.Fail()
+	}
+
+	p, found := ResolvePath(reflect.TypeOf(A{}), "json", "b.c.v")
+	if !found || p.Field().Name != "V" {
+		fmt.Println("ResolvePath failed to resolve a dotted key.")
+		t.Fail()
+	}
+	if _, found = ResolvePath(reflect.TypeOf(A{}), "json", "b.x"); found {
+		fmt.Println("ResolvePath should reject unknown segments.")
+		t.Fail()
+	}
+
+	v, ok := p.Value(reflect.ValueOf(A{B: B{C: C{V: 7}}}))
+	if !ok || v.Int() != 7 {
+		fmt.Println("TaggedPath.Value failed to read a nested field.")
+		t.Fail()
+	}
+}
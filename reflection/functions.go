@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 )
 
 // GetTypeName returns the input type's name.
@@ -31,6 +33,9 @@ func GetFunctionName() string {
 }
 
 // MapTagToType extracts the field tag and type belonging to each field marked with a given struct tag key.
+//
+// Fields promoted from embedded structs are included, see TaggedFields.
+// Fields of nested structs are included under dotted keys, such as 'address.city', see TaggedPaths.
 func MapTagToType(tagKey string, inputStruct any) map[string]string {
 
 	t := reflect.TypeOf(inputStruct)
@@ -42,28 +47,71 @@ func MapTagToType(tagKey string, inputStruct any) map[string]string {
 	}
 
 	m := map[string]string{}
-	for i := 0; i < t.NumField(); i++ {
-
-		full := t.Field(i).Tag.Get(tagKey)
-		split := strings.Split(full, ",")
-		tag := split[0]
-		if strings.TrimSpace(tag) == "" {
-			continue
-		}
-		// Typically used to show that a field is supposed to be ignored.
-		// For example: Age int `json:"-"`
-		if strings.TrimSpace(tag) == "-" {
-			continue
-		}
-
-		tn := t.Field(i).Type.Name()
-		m[tag] = tn
+	for _, p := range TaggedPaths(t, tagKey, MaxPathDepth) {
+		m[p.Key] = p.Field().Type.Name()
 	}
 
 	return m
 }
 
 func AutoMap[T any](source any) (target T, err error) {
+	return AutoMapWith[T](source, Options{})
+}
+
+// Options adjust the behaviour of a single AutoMapWith call.
+type Options struct {
+	// Converters take precedence over those registered with RegisterConverter.
+	Converters *Converters
+	// Strict fails the mapping when a tagged field of either struct has no counterpart.
+	Strict bool
+	// Report, when set, is filled with what happened to each field.
+	Report *Report
+	// Policy only matters to MapInto, since AutoMap always starts from a zero target.
+	Policy Policy
+}
+
+// Policy decides which source fields MapInto lets overwrite an existing target.
+type Policy int
+
+const (
+	// SkipNil leaves the target field untouched when the source is nil. This is how AutoMap behaves.
+	SkipNil Policy = iota
+	// Overwrite copies every field, setting the target field to its zero value when the source is nil.
+	Overwrite
+	// SkipZero leaves the target field untouched when the source is nil or holds its zero value.
+	SkipZero
+)
+
+// MapInto maps the source onto an existing target, overlaying the fields allowed by the policy in the options.
+//
+// Nested structs with automap tags are merged field by field, even when source and target share their type.
+// Slices, arrays, maps and untagged structs such as time.Time are replaced as a whole.
+// Pointers of the target are replaced by pointers to updated copies, rather than written through,
+// so on failure neither the target nor anything it points to is changed.
+func MapInto[T any](source any, target *T, opts Options) error {
+
+	if target == nil {
+		return fmt.Errorf("(target was nil)")
+	}
+
+	mapped, err := mapWith(source, *target, opts, true)
+	if err != nil {
+		return err
+	}
+
+	*target = mapped
+	return nil
+}
+
+// AutoMapWith is AutoMap with per-call options.
+func AutoMapWith[T any](source any, opts Options) (target T, err error) {
+	return mapWith(source, target, opts, false)
+}
+
+// mapWith maps the source onto the target, which is received and returned by value.
+//
+//	When merging, nested structs of the same type are merged by their tags instead of copied whole.
+func mapWith[T any](source any, target T, opts Options, merge bool) (T, error) {
 
 	// Peel the source until we reach the struct.
 	sourceStruct := reflect.ValueOf(&source)
@@ -90,73 +138,378 @@ func AutoMap[T any](source any) (target T, err error) {
 	targetStruct := reflect.ValueOf(&target).Elem()
 
 	// Enter the recursive part.
-	err = autoMap(sourceStruct, targetStruct)
-	return target, err
+	m := mapper{converters: opts.Converters, report: opts.Report, policy: opts.Policy, merge: merge}
+	if opts.Strict && m.report == nil {
+		m.report = &Report{}
+	}
+	err := m.autoMap(sourceStruct, targetStruct, "")
+	if err != nil {
+		return target, err
+	}
+	if m.report != nil {
+		m.unmatched(sourceStruct.Type(), targetStruct.Type(), "", map[[2]reflect.Type]bool{})
+	}
+
+	if opts.Strict && (len(m.report.UnmatchedSource) > 0 || len(m.report.UnmatchedTarget) > 0) {
+		cause := fmt.Errorf(
+			"(strict mapping failed - unmatched source fields: '%s', unmatched target fields: '%s')",
+			strings.Join(m.report.UnmatchedSource, " "), strings.Join(m.report.UnmatchedTarget, " "),
+		)
+		return target, cause
+	}
+
+	return target, nil
 }
 
-func autoMap(s, t reflect.Value) error {
+// fieldPair holds a source and a target field sharing an automap tag.
+type fieldPair struct {
+	source TaggedField
+	target TaggedField
+}
 
-	sourceMap := mapTagToFieldIndex("automap", s.Interface())
-	targetMap := mapTagToFieldIndex("automap", t.Interface())
+// plan describes how to map one struct type onto another.
+type plan struct {
+	pairs []fieldPair
+	// Tagged fields without a counterpart.
+	unmatchedSource []TaggedField
+	unmatchedTarget []TaggedField
+}
+
+// plans caches the plan of every (source type, target type) seen by autoMap.
+var plans sync.Map
+
+// planFor returns the plan from the source type to the target type, ordered by field.
+func planFor(s, t reflect.Type) *plan {
+
+	key := [2]reflect.Type{s, t}
+	if cached, found := plans.Load(key); found {
+		return cached.(*plan)
+	}
 
-	for k := range sourceMap {
+	sourceFields := TaggedFields(s, "automap")
+	targetFields := TaggedFields(t, "automap")
+	targetMap := map[string]TaggedField{}
+	for _, f := range targetFields {
+		targetMap[f.Tag] = f
+	}
+	sourceMap := map[string]bool{}
 
-		sourceIndex := sourceMap[k]
-		targetIndex, found := targetMap[k]
+	p := &plan{}
+	for _, sf := range sourceFields {
+		sourceMap[sf.Tag] = true
+		tf, found := targetMap[sf.Tag]
 		if !found {
+			p.unmatchedSource = append(p.unmatchedSource, sf)
 			continue
 		}
+		p.pairs = append(p.pairs, fieldPair{source: sf, target: tf})
+	}
+	for _, tf := range targetFields {
+		if !sourceMap[tf.Tag] {
+			p.unmatchedTarget = append(p.unmatchedTarget, tf)
+		}
+	}
 
-		// Pick out the matching fields from the structs.
-		sourceField := s.Field(sourceIndex)
-		targetField := t.Field(targetIndex)
+	// Concurrent callers may compute the same plan; either result is equally valid.
+	plans.Store(key, p)
 
-		// Peel off pointers to the source struct's current field.
-		for sourceField.Kind() == reflect.Pointer && !sourceField.IsNil() {
-			sourceField = sourceField.Elem()
+	return p
+}
+
+// unmatched reports the tagged fields without a counterpart, following the plans of nested cognate structs
+// through pointers and collections, but not through converters.
+//
+// It only looks at the types, so that strict mappings pass or fail regardless of the data: a nested struct
+// counts even when the source holds nil, and elements of collections are reported once, as 'Items[].Zip'.
+func (m mapper) unmatched(s, t reflect.Type, path string, visited map[[2]reflect.Type]bool) {
+
+	// Guards against self-referential types, such as 'type Node struct { Next *Node }'.
+	key := [2]reflect.Type{s, t}
+	if visited[key] {
+		return
+	}
+	visited[key] = true
+	defer delete(visited, key)
+
+	p := planFor(s, t)
+	for _, f := range p.unmatchedSource {
+		m.report.UnmatchedSource = append(m.report.UnmatchedSource, joinPath(path, f.Field.Name))
+	}
+	for _, f := range p.unmatchedTarget {
+		m.report.UnmatchedTarget = append(m.report.UnmatchedTarget, joinPath(path, f.Field.Name))
+	}
+
+	for _, fp := range p.pairs {
+		m.unmatchedWithin(fp.source.Field.Type, fp.target.Field.Type, joinPath(path, fp.source.Field.Name), visited)
+	}
+}
+
+// unmatchedWithin follows a pair of field or element types the way mapValue does.
+func (m mapper) unmatchedWithin(s, t reflect.Type, path string, visited map[[2]reflect.Type]bool) {
+
+	s, t = peelType(s), peelType(t)
+	if _, found := m.converters.lookup(s, t); found {
+		return
+	}
+	if _, found := globalConverters.lookup(s, t); found {
+		return
+	}
+	if s.Kind() != t.Kind() {
+		return
+	}
+
+	switch s.Kind() {
+	case reflect.Struct:
+		if s != t || (m.merge && len(planFor(s, t).pairs) > 0) {
+			m.unmatched(s, t, path, visited)
 		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if s != t {
+			m.unmatchedWithin(s.Elem(), t.Elem(), joinPath(path, "[]"), visited)
+		}
+	}
+}
+
+// Report lists what happened to each field during a mapping, by field path, e.g. 'Address.Street'.
+type Report struct {
+	Mapped []string
+	// SkippedNil holds source fields left out because they were nil.
+	SkippedNil []string
+	// SkippedZero holds source fields left out by the SkipZero policy.
+	SkippedZero []string
+	// UnmatchedSource and UnmatchedTarget hold tagged fields without a counterpart on the other side,
+	// found from the types alone.
+	UnmatchedSource []string
+	UnmatchedTarget []string
+}
 
-		// Nothing to do when the source is nil.
-		if Nillable(sourceField) && sourceField.IsNil() {
+// mapper carries the options of a single AutoMap call through the recursion.
+type mapper struct {
+	converters *Converters
+	report     *Report
+	policy     Policy
+	merge      bool
+}
+
+// mappingError defers building the field path until the error surfaces, keeping successful mappings cheap.
+type mappingError struct {
+	// segments of the field path, innermost first.
+	segments []string
+	reason   string
+	detail   string
+}
+
+func (e *mappingError) Error() string {
+
+	path := ""
+	for i := len(e.segments) - 1; i >= 0; i-- {
+		path = joinPath(path, e.segments[i])
+	}
+
+	return fmt.Sprintf("(%s at '%s' - %s)", e.reason, path, e.detail)
+}
+
+// within records that the error occurred within the given field or element.
+func within(err error, segment string) error {
+
+	if me, ok := err.(*mappingError); ok {
+		me.segments = append(me.segments, segment)
+	}
+
+	return err
+}
+
+func joinPath(path, segment string) string {
+
+	if path == "" || strings.HasPrefix(segment, "[") {
+		return path + segment
+	}
+
+	return path + "." + segment
+}
+
+// join returns the path of a field or element for the report. Paths are only built when there is a report.
+func (m mapper) join(path, segment string) string {
+
+	if m.report == nil {
+		return ""
+	}
+
+	return joinPath(path, segment)
+}
+
+func (m mapper) autoMap(s, t reflect.Value, path string) error {
+
+	p := planFor(s.Type(), t.Type())
+
+	for _, fp := range p.pairs {
+
+		// Pick out the matching fields from the structs.
+		name := fp.source.Field.Name
+		sourceField, ok := FieldByIndex(s, fp.source.Index)
+		if !ok {
+			// The field is promoted from a nil embedded pointer, so it is nil as well.
+			if m.report != nil {
+				m.report.SkippedNil = append(m.report.SkippedNil, m.join(path, name))
+			}
+			continue
+		}
+		targetField, ok := fieldByIndexAlloc(t, fp.target.Index, m.merge)
+		if !ok {
 			continue
 		}
 
-		// Prepare the target field when it's nil and needs to be used.
-		if Nillable(targetField) && targetField.IsNil() {
-			targetField.Set(reflect.New(targetField.Type().Elem()))
+		err := m.mapValue(sourceField, targetField, m.join(path, name))
+		if err != nil {
+			return within(err, name)
+		}
+	}
+
+	return nil
+}
+
+// mapValue copies the source into the target, peeling pointers, applying converters, and mapping cognate structs and collections.
+func (m mapper) mapValue(source, target reflect.Value, path string) error {
+
+	// Peel off pointers to the source.
+	for source.Kind() == reflect.Pointer && !source.IsNil() {
+		source = source.Elem()
+	}
+
+	// Nothing to do when the source is nil, unless the policy says to clear the target.
+	if Nillable(source) && source.IsNil() {
+		if m.policy == Overwrite {
+			target.Set(reflect.Zero(target.Type()))
+			m.mapped(path, nil)
+			return nil
+		}
+		if m.report != nil {
+			m.report.SkippedNil = append(m.report.SkippedNil, path)
+		}
+		return nil
+	}
+
+	if m.policy == SkipZero && source.IsZero() {
+		if m.report != nil {
+			m.report.SkippedZero = append(m.report.SkippedZero, path)
 		}
+		return nil
+	}
 
-		// Peel off pointers to the target struct's current field.
-		for targetField.Kind() == reflect.Pointer && !targetField.IsNil() {
-			targetField = targetField.Elem()
+	// Peel off pointers to the target, preparing them when they're nil. When merging, existing pointers are
+	// replaced by copies, as what they point to may be shared.
+	for target.Kind() == reflect.Pointer {
+		if target.IsNil() || m.merge {
+			target.Set(copyOf(target))
 		}
+		target = target.Elem()
+	}
 
-		// Assert that the fields match.
-		if sourceField.Kind() != targetField.Kind() {
-			cause := fmt.Errorf(
-				"(source and target kind mismatch - source: '%s', target: '%s')",
-				sourceField.Kind(), targetField.Kind(),
-			)
-			return cause
+	// Registered converters take precedence over everything else.
+	converted, err := convert(m.converters, source, target)
+	if converted {
+		m.mapped(path, err)
+		return err
+	}
+
+	// Assert that the values match.
+	if source.Kind() != target.Kind() {
+		cause := &mappingError{
+			reason: "source and target kind mismatch",
+			detail: fmt.Sprintf("source: '%s', target: '%s'", source.Kind(), target.Kind()),
 		}
+		return cause
+	}
 
-		// Recurse into nested cognate structs.
-		sameType := sourceField.Type() == targetField.Type()
-		if sourceField.Kind() == reflect.Struct && !sameType {
+	mergeable := m.merge && source.Kind() == reflect.Struct && len(planFor(source.Type(), target.Type()).pairs) > 0
+	if source.Type() == target.Type() && !mergeable {
+		target.Set(source)
+		m.mapped(path, nil)
+		return nil
+	}
+
+	switch source.Kind() {
 
-			err := autoMap(sourceField, targetField)
+	// Recurse into nested cognate structs.
+	case reflect.Struct:
+		return m.autoMap(source, target, path)
+
+	// Map cognate collections element by element. Nil elements stay nil.
+	case reflect.Slice:
+		mapped := reflect.MakeSlice(target.Type(), source.Len(), source.Len())
+		for i := 0; i < source.Len(); i++ {
+			segment := "[" + strconv.Itoa(i) + "]"
+			err := m.mapValue(source.Index(i), mapped.Index(i), m.join(path, segment))
 			if err != nil {
-				return err
+				return within(err, segment)
+			}
+		}
+		target.Set(mapped)
+		return nil
+
+	case reflect.Array:
+		if source.Len() != target.Len() {
+			cause := &mappingError{
+				reason: "source and target array length mismatch",
+				detail: fmt.Sprintf("source: '%d', target: '%d'", source.Len(), target.Len()),
+			}
+			return cause
+		}
+		for i := 0; i < source.Len(); i++ {
+			segment := "[" + strconv.Itoa(i) + "]"
+			err := m.mapValue(source.Index(i), target.Index(i), m.join(path, segment))
+			if err != nil {
+				return within(err, segment)
 			}
-			continue
 		}
+		return nil
 
-		targetField.Set(sourceField)
+	case reflect.Map:
+		if source.Type().Key() != target.Type().Key() {
+			cause := &mappingError{
+				reason: "source and target map key mismatch",
+				detail: fmt.Sprintf("source: '%s', target: '%s'", source.Type().Key(), target.Type().Key()),
+			}
+			return cause
+		}
+		mapped := reflect.MakeMapWithSize(target.Type(), source.Len())
+		iter := source.MapRange()
+		for iter.Next() {
+			element := reflect.New(target.Type().Elem()).Elem()
+			var elementPath string
+			if m.report != nil {
+				elementPath = m.join(path, fmt.Sprintf("[%v]", iter.Key()))
+			}
+			err := m.mapValue(iter.Value(), element, elementPath)
+			if err != nil {
+				return within(err, fmt.Sprintf("[%v]", iter.Key()))
+			}
+			mapped.SetMapIndex(iter.Key(), element)
+		}
+		target.Set(mapped)
+		return nil
+	}
+
+	if !source.Type().AssignableTo(target.Type()) {
+		cause := &mappingError{
+			reason: "source and target type mismatch",
+			detail: fmt.Sprintf("source: '%s', target: '%s'", source.Type(), target.Type()),
+		}
+		return cause
 	}
 
+	target.Set(source)
+	m.mapped(path, nil)
 	return nil
 }
 
+func (m mapper) mapped(path string, err error) {
+
+	if m.report != nil && err == nil {
+		m.report.Mapped = append(m.report.Mapped, path)
+	}
+}
+
 func Nillable(input reflect.Value) (nillable bool) {
 
 	switch input.Kind() {
@@ -177,40 +530,6 @@ func Nillable(input reflect.Value) (nillable bool) {
 	return nillable
 }
 
-func mapTagToFieldIndex(tagKey string, inputStruct any) map[string]int {
-
-	t := reflect.TypeOf(inputStruct)
-	if t == nil {
-		return nil
-	}
-	for t.Kind() == reflect.Pointer {
-		t = t.Elem()
-	}
-	if t.Kind() != reflect.Struct {
-		return nil
-	}
-
-	m := map[string]int{}
-	for i := 0; i < t.NumField(); i++ {
-
-		full := t.Field(i).Tag.Get(tagKey)
-		split := strings.Split(full, ",")
-		tag := split[0]
-		if strings.TrimSpace(tag) == "" {
-			continue
-		}
-		// Typically used to show that a field is supposed to be ignored.
-		// For example: Age int `json:"-"`
-		if strings.TrimSpace(tag) == "-" {
-			continue
-		}
-
-		m[tag] = i
-	}
-
-	return m
-}
-
 func Describe(id string, input reflect.Value) {
 
 	format := "%20s - %6s: '%s'\n"
@@ -0,0 +1,168 @@
+package reflection
+
+import (
+	"fmt"
+	"testing"
+)
+
+func ExampleMapInto() {
+
+	type Patch struct {
+		Name *string `automap:"name"`
+		Age  *int    `automap:"age"`
+	}
+	type User struct {
+		Name string `automap:"name"`
+		Age  int    `automap:"age"`
+	}
+
+	name := "Jeff"
+	user := User{Name: "Geoff", Age: 42}
+
+	err := MapInto(Patch{Name: &name}, &user, Options{Policy: SkipNil})
+	if err != nil {
+		// Handle err
+	}
+	fmt.Println(user.Name, user.Age)
+
+	// Output:
+	// Jeff 42
+}
+
+func Test_MapInto_Policies(t *testing.T) {
+
+	type Address struct {
+		Street string `automap:"street"`
+		City   string `automap:"city"`
+	}
+	type Source struct {
+		Name    string   `automap:"name"`
+		Mail    *string  `automap:"mail"`
+		Age     int      `automap:"age"`
+		Address *Address `automap:"address"`
+	}
+	type Target struct {
+		Name    string  `automap:"name"`
+		Mail    *string `automap:"mail"`
+		Age     int     `automap:"age"`
+		Address Address `automap:"address"`
+	}
+
+	mail := "old@mail"
+	initial := func() Target {
+		m := mail
+		return Target{Name: "Old", Mail: &m, Age: 40, Address: Address{Street: "Old St", City: "Oslo"}}
+	}
+	source := Source{Name: "", Age: 41, Address: &Address{Street: "New St"}}
+
+	tests := []struct {
+		policy Policy
+		name   string
+		mail   bool
+		age    int
+		street string
+		city   string
+	}{
+		{SkipNil, "", true, 41, "New St", ""},
+		{Overwrite, "", false, 41, "New St", ""},
+		{SkipZero, "Old", true, 41, "New St", "Oslo"},
+	}
+
+	for _, test := range tests {
+		target := initial()
+		err := MapInto(source, &target, Options{Policy: test.policy})
+		if err != nil {
+			fmt.Println(err)
+			t.Fail()
+			continue
+		}
+		got := fmt.Sprint(target.Name, target.Mail != nil, target.Age, target.Address.Street, target.Address.City)
+		expected := fmt.Sprint(test.name, test.mail, test.age, test.street, test.city)
+		if got != expected {
+			fmt.Printf("policy %d: expected %s, got %s\n", test.policy, expected, got)
+			t.Fail()
+		}
+	}
+
+	target := initial()
+	type Bad struct {
+		Age string `automap:"age"`
+	}
+	err := MapInto(Bad{Age: "x"}, &target, Options{})
+	if err == nil || target.Age != 40 {
+		fmt.Println("MapInto should fail without altering the target, got:", err, target.Age)
+		t.Fail()
+	}
+
+	err = MapInto[Target](source, nil, Options{})
+	if err == nil {
+		fmt.Println("MapInto should not allow a nil target.")
+		t.Fail()
+	}
+}
+
+func Test_MapInto_Shared_Pointers(t *testing.T) {
+
+	type Profile struct {
+		Bio   string `automap:"bio"`
+		Score int    `automap:"score"`
+	}
+	type Meta struct {
+		Version int `automap:"version"`
+	}
+	type Entity struct {
+		*Meta
+		Profile *Profile `automap:"profile"`
+		Age     *int     `automap:"age"`
+	}
+	type PatchProfile struct {
+		Bio   string `automap:"bio"`
+		Score string `automap:"score"`
+	}
+	type BadPatch struct {
+		Version int          `automap:"version"`
+		Profile PatchProfile `automap:"profile"`
+		Age     int          `automap:"age"`
+	}
+
+	age := 30
+	meta := Meta{Version: 1}
+	profile := Profile{Bio: "old", Score: 5}
+	stored := Entity{Meta: &meta, Profile: &profile, Age: &age}
+
+	// Fails on the profile's score, after the version, the bio and the age were mapped.
+	target := stored
+	err := MapInto(BadPatch{Version: 2, Profile: PatchProfile{Bio: "new", Score: "x"}, Age: 31}, &target, Options{})
+	if err == nil {
+		fmt.Println("expected the mapping to fail")
+		t.FailNow()
+	}
+	if meta.Version != 1 || profile.Bio != "old" || age != 30 || target != stored {
+		fmt.Println("a failed MapInto should change neither the target nor what it points to, got:", meta, profile, age)
+		t.Fail()
+	}
+
+	target = stored
+	type GoodPatch struct {
+		Version int `automap:"version"`
+		Profile struct {
+			Bio string `automap:"bio"`
+		} `automap:"profile"`
+		Age int `automap:"age"`
+	}
+	good := GoodPatch{Version: 2, Age: 31}
+	good.Profile.Bio = "new"
+	err = MapInto(good, &target, Options{})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if target.Version != 2 || target.Profile.Bio != "new" || target.Profile.Score != 5 || *target.Age != 31 {
+		fmt.Printf("expected the target to be merged, got: %+v %+v %d\n", *target.Meta, *target.Profile, *target.Age)
+		t.Fail()
+	}
+	if meta.Version != 1 || profile.Bio != "old" || age != 30 {
+		fmt.Println("MapInto should not write through the pointers of the target, got:", meta, profile, age)
+		t.Fail()
+	}
+}
@@ -0,0 +1,109 @@
+package reflection
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func ExampleAutoMapWith() {
+
+	type Address struct {
+		Street string `automap:"street"`
+		Zip    string `automap:"zip"`
+	}
+	type Model struct {
+		Name    string  `automap:"name"`
+		Mail    *string `automap:"mail"`
+		Address Address `automap:"address"`
+		Typo    string  `automap:"nmae"`
+	}
+
+	type AddressEntity struct {
+		Street string `automap:"street"`
+		City   string `automap:"city"`
+	}
+	type Entity struct {
+		Name    string        `automap:"name"`
+		Mail    *string       `automap:"mail"`
+		Address AddressEntity `automap:"address"`
+	}
+
+	var report Report
+	_, err := AutoMapWith[Entity](Model{}, Options{Strict: true, Report: &report})
+	fmt.Println("1:", err)
+	fmt.Println("2:", report.Mapped)
+	fmt.Println("3:", report.SkippedNil)
+
+	// Output:
+	// 1: (strict mapping failed - unmatched source fields: 'Typo Address.Zip', unmatched target fields: 'Address.City')
+	// 2: [Name Address.Street]
+	// 3: [Mail]
+}
+
+func Test_AutoMap_Strict_Types(t *testing.T) {
+
+	type Address struct {
+		Street string `automap:"street"`
+		Zip    string `automap:"zip"`
+	}
+	type AddressEntity struct {
+		Street string `automap:"street"`
+	}
+	type Source struct {
+		Home  *Address           `automap:"home"`
+		Other []Address          `automap:"other"`
+		ByTag map[string]Address `automap:"by_tag"`
+	}
+	type Target struct {
+		Home  AddressEntity            `automap:"home"`
+		Other []AddressEntity          `automap:"other"`
+		ByTag map[string]AddressEntity `automap:"by_tag"`
+	}
+
+	expected := "unmatched source fields: 'Home.Zip Other[].Zip ByTag[].Zip'"
+	sources := []Source{
+		{},
+		{Home: &Address{}, Other: []Address{{}}, ByTag: map[string]Address{"a": {}}},
+	}
+	for _, source := range sources {
+		_, err := AutoMapWith[Target](source, Options{Strict: true})
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			fmt.Printf("%+v: strict mapping should fail from the types alone, got: %v\n", source, err)
+			t.Fail()
+		}
+	}
+}
+
+func Test_AutoMap_Error_Paths(t *testing.T) {
+
+	type Inner struct {
+		Value int `automap:"value"`
+	}
+	type Source struct {
+		Items []Inner `automap:"items"`
+	}
+
+	type BadInner struct {
+		Value string `automap:"value"`
+	}
+	type Target struct {
+		Items []BadInner `automap:"items"`
+	}
+
+	_, err := AutoMap[Target](Source{Items: []Inner{{1}, {2}}})
+	if err == nil || !strings.Contains(err.Error(), "'Items[0].Value'") {
+		fmt.Println("AutoMap errors should hold the full field path, got:", err)
+		t.Fail()
+	}
+
+	type Loose struct {
+		Items []BadInner `automap:"items"`
+		Extra string     `automap:"extra"`
+	}
+	_, err = AutoMapWith[Loose](Source{}, Options{})
+	if err != nil {
+		fmt.Println("Unmatched fields should only fail in strict mode, got:", err)
+		t.Fail()
+	}
+}
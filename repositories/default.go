@@ -95,3 +95,29 @@ func (r *Repo[M, E]) Search(ctx context.Context, query []t.Query) ([]M, error) {
 
 	return ms, nil
 }
+
+// SearchPage pages through the DAO when it is an interfaces.Pager, and through its plain Search otherwise.
+func (r *Repo[M, E]) SearchPage(ctx context.Context, query []t.Query, opts t.SearchOptions) (page t.Page[M], err error) {
+
+	var ep t.Page[E]
+	if pager, ok := r.dao.(i.Pager[E]); ok {
+		ep, err = pager.SearchPage(ctx, query, opts)
+	} else {
+		ep, err = t.SearchPageOf(ctx, r.dao.Search, query, opts)
+	}
+	if err != nil {
+		return page, err
+	}
+
+	for _, e := range ep.Items {
+		m, err := reflection.AutoMap[M](e)
+		if err != nil {
+			return page, err
+		}
+		page.Items = append(page.Items, m)
+	}
+	page.Total = ep.Total
+	page.NextCursor = ep.NextCursor
+
+	return page, nil
+}
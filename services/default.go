@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	i "github.com/pergamenum/go-consensus-standards/interfaces"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+	t "github.com/pergamenum/go-consensus-standards/types"
+)
+
+// IDGenerator returns a new, unique id for a model about to be created.
+type IDGenerator func() (string, error)
+
+// Default is the generic implementation of interfaces.Service on top of an interfaces.Repository.
+type Default[M any] struct {
+	repo   i.Repository[M]
+	newID  IDGenerator
+	tagKey string
+	idKey  string
+	schema *t.Schema
+}
+
+type DefaultConfig[M any] struct {
+	Repository i.Repository[M]
+	// IDGenerator defaults to RandomID.
+	IDGenerator IDGenerator
+	// TagKey is the struct tag key used to validate queries and locate the id field. Defaults to "json".
+	// Ignored when Schema is set: its tag key is used instead.
+	TagKey string
+	// IDKey is the tag of the model's id field, and the update key holding the target id. Defaults to "id".
+	IDKey string
+	// Operators are the operators allowed in queries. Defaults to constants.ValidOperators.
+	// Ignored when Schema is set.
+	Operators map[string]bool
+	// Schema validates queries and search options, and its tag key locates the id field.
+	// It takes precedence over TagKey and Operators. Defaults to types.DefaultSchema of those.
+	Schema *t.Schema
+}
+
+func NewDefault[M any](conf DefaultConfig[M]) *Default[M] {
+
+	newID := conf.IDGenerator
+	if newID == nil {
+		newID = RandomID
+	}
+	idKey := conf.IDKey
+	if idKey == "" {
+		idKey = "id"
+	}
+	schema := conf.Schema
+	if schema == nil {
+		schema = t.DefaultSchema[M](t.SchemaConfig{TagKey: conf.TagKey, Operators: conf.Operators})
+	}
+
+	return &Default[M]{
+		repo:   conf.Repository,
+		newID:  newID,
+		tagKey: schema.TagKey(),
+		idKey:  idKey,
+		schema: schema,
+	}
+}
+
+// RandomID returns 32 random hexadecimal characters.
+func RandomID() (string, error) {
+
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Create generates an id for the model, and stores it in the model's id field when there is one.
+func (s *Default[M]) Create(ctx context.Context, model M) error {
+
+	_, err := s.CreateWithID(ctx, model)
+
+	return err
+}
+
+// CreateWithID is like Create, and returns the generated id.
+func (s *Default[M]) CreateWithID(ctx context.Context, model M) (string, error) {
+
+	id, err := s.newID()
+	if err != nil {
+		cause := fmt.Sprintf("(failed to generate id: %s)", err)
+		return "", e.Wrap(cause, e.ErrInternal)
+	}
+
+	s.setID(&model, id)
+
+	err = s.repo.Create(ctx, id, model)
+	if err != nil {
+		return "", classify(err)
+	}
+
+	return id, nil
+}
+
+func (s *Default[M]) Read(ctx context.Context, id string) (model M, err error) {
+
+	if id == "" {
+		return model, e.Wrap("(id is empty)", e.ErrBadRequest)
+	}
+
+	model, err = s.repo.Read(ctx, id)
+	if err != nil {
+		return model, classify(err)
+	}
+
+	return model, nil
+}
+
+// Update applies the update to the model whose id is stored in the update under the id key.
+func (s *Default[M]) Update(ctx context.Context, update t.Update) error {
+
+	id, ok := update[s.idKey].(string)
+	if !ok || id == "" {
+		cause := fmt.Sprintf("(update must hold a non-empty string id under '%s')", s.idKey)
+		return e.Wrap(cause, e.ErrBadRequest)
+	}
+
+	// The id selects the target; it is never itself updated.
+	rest := t.Update{}
+	for k, v := range update {
+		if k != s.idKey {
+			rest[k] = v
+		}
+	}
+
+	err := s.repo.Update(ctx, id, rest)
+	if err != nil {
+		return classify(err)
+	}
+
+	return nil
+}
+
+func (s *Default[M]) Delete(ctx context.Context, id string) error {
+
+	if id == "" {
+		return e.Wrap("(id is empty)", e.ErrBadRequest)
+	}
+
+	err := s.repo.Delete(ctx, id)
+	if err != nil {
+		return classify(err)
+	}
+
+	return nil
+}
+
+// Search validates the queries against the model's tags before searching. The input is left unaltered.
+func (s *Default[M]) Search(ctx context.Context, query []t.Query) ([]M, error) {
+
+	validated, err := s.validate(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ms, err := s.repo.Search(ctx, validated)
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	return ms, nil
+}
+
+// SearchPage validates the queries and sort keys against the model's tags before searching. The input is left unaltered.
+//
+//	Repositories that are not an interfaces.Pager are paged through their plain Search, without sorting or filtering.
+func (s *Default[M]) SearchPage(ctx context.Context, query []t.Query, opts t.SearchOptions) (page t.Page[M], err error) {
+
+	validated, err := s.validate(query)
+	if err != nil {
+		return page, err
+	}
+
+	opts, err = s.schema.ValidateOptions(opts)
+	if err != nil {
+		return page, err
+	}
+
+	if pager, ok := s.repo.(i.Pager[M]); ok {
+		page, err = pager.SearchPage(ctx, validated, opts)
+	} else {
+		page, err = t.SearchPageOf(ctx, s.repo.Search, validated, opts)
+	}
+	if err != nil {
+		return page, classify(err)
+	}
+
+	return page, nil
+}
+
+func (s *Default[M]) validate(query []t.Query) ([]t.Query, error) {
+
+	validated, err := s.schema.Validate(query)
+	if err != nil {
+		// Unwraps to ErrBadRequest.
+		return nil, err
+	}
+
+	return validated, nil
+}
+
+// setID stores the id in the model's string field tagged with the id key, if any.
+func (s *Default[M]) setID(model *M, id string) {
+
+	v := reflect.ValueOf(model).Elem()
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, f := range reflection.TaggedFields(v.Type(), s.tagKey) {
+
+		if f.Tag != s.idKey {
+			continue
+		}
+
+		field, ok := reflection.FieldByIndexAlloc(v, f.Index)
+		if ok && field.Kind() == reflect.String && field.CanSet() {
+			field.SetString(id)
+		}
+		return
+	}
+}
+
+var sentinels = []error{
+	e.ErrConflict,
+	e.ErrNotFound,
+	e.ErrInternal,
+	e.ErrCorrupt,
+	e.ErrBadRequest,
+	e.ErrBadGateway,
+}
+
+// classify returns errors that already carry a sentinel as they are, and wraps the rest as internal errors.
+func classify(err error) error {
+
+	for _, s := range sentinels {
+		if errors.Is(err, s) {
+			return err
+		}
+	}
+
+	return e.Wrap(err, e.ErrInternal)
+}
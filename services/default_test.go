@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pergamenum/go-consensus-standards/daos"
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	"github.com/pergamenum/go-consensus-standards/repositories"
+	"github.com/pergamenum/go-consensus-standards/types"
+)
+
+type userModel struct {
+	ID   string `json:"id" automap:"id"`
+	Name string `json:"name" automap:"name"`
+	Age  int    `json:"age" automap:"age"`
+}
+
+type userEntity struct {
+	ID   string `automap:"id"`
+	Name string `automap:"name"`
+	Age  int    `automap:"age"`
+}
+
+func newStack() *Default[userModel] {
+
+	dao := daos.NewMemory[userEntity](daos.MemoryConfig{})
+	repo := repositories.NewRepo[userModel, userEntity](repositories.RepoConfig[userModel, userEntity]{DAO: dao})
+
+	n := 0
+	return NewDefault[userModel](DefaultConfig[userModel]{
+		Repository: repo,
+		IDGenerator: func() (string, error) {
+			n++
+			return fmt.Sprint(n), nil
+		},
+	})
+}
+
+func Test_Default_Stack(t *testing.T) {
+
+	ctx := context.Background()
+	s := newStack()
+
+	for _, name := range []string{"Alice", "Bob"} {
+		err := s.Create(ctx, userModel{Name: name, Age: 30})
+		if err != nil {
+			fmt.Println(err)
+			t.Fail()
+		}
+	}
+
+	id, err := s.CreateWithID(ctx, userModel{Name: "Carol", Age: 20})
+	if err != nil || id != "3" {
+		fmt.Println("CreateWithID should return the generated id, got:", id, err)
+		t.Fail()
+	}
+	if c, _ := s.Read(ctx, id); c.ID != id || c.Name != "Carol" {
+		fmt.Printf("expected the returned id to address the model, got: %+v\n", c)
+		t.Fail()
+	}
+
+	u, err := s.Read(ctx, "2")
+	if err != nil || u.ID != "2" || u.Name != "Bob" {
+		fmt.Printf("expected the generated id to be stored, got: %+v, %v\n", u, err)
+		t.Fail()
+	}
+
+	err = s.Update(ctx, types.Update{"id": "2", "age": 31})
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+
+	queries := []types.Query{{Key: "age", Operator: "GT", Value: "30"}}
+	us, err := s.Search(ctx, queries)
+	if err != nil || len(us) != 1 || us[0].Name != "Bob" {
+		fmt.Printf("unexpected search result: %+v, %v\n", us, err)
+		t.Fail()
+	}
+	if queries[0].Value != "30" {
+		fmt.Println("Search should not alter its input.")
+		t.Fail()
+	}
+
+	err = s.Delete(ctx, "2")
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+
+	_, err = s.Read(ctx, "2")
+	if !errors.Is(err, e.ErrNotFound) {
+		fmt.Println("expected not found, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_Default_Errors(t *testing.T) {
+
+	ctx := context.Background()
+	s := newStack()
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{"update without id", s.Update(ctx, types.Update{"age": 1}), e.ErrBadRequest},
+		{"update missing", s.Update(ctx, types.Update{"id": "9", "age": 1}), e.ErrNotFound},
+		{"read empty id", func() error { _, err := s.Read(ctx, ""); return err }(), e.ErrBadRequest},
+		{"search bad key", func() error {
+			_, err := s.Search(ctx, []types.Query{{Key: "nope", Operator: "EQ", Value: "1"}})
+			return err
+		}(), e.ErrBadRequest},
+		{"search page bad sort", func() error {
+			_, err := s.SearchPage(ctx, nil, types.SearchOptions{Sort: []types.Sort{{Key: "nope"}}})
+			return err
+		}(), e.ErrBadRequest},
+		{"create failing generator", NewDefault[userModel](DefaultConfig[userModel]{
+			IDGenerator: func() (string, error) { return "", errors.New("exhausted") },
+		}).Create(ctx, userModel{}), e.ErrInternal},
+	}
+
+	for _, test := range tests {
+		if !errors.Is(test.err, test.expected) {
+			fmt.Printf("%s: expected %v, got: %v\n", test.name, test.expected, test.err)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Default_Schema_Tag_Key(t *testing.T) {
+
+	s := NewDefault[userModel](DefaultConfig[userModel]{TagKey: "json", Schema: types.SchemaOf[userModel]("automap")})
+	if s.tagKey != "automap" {
+		fmt.Println("The id field should be located with the schema's tag key, got:", s.tagKey)
+		t.Fail()
+	}
+
+	s = NewDefault[userModel](DefaultConfig[userModel]{})
+	if s.schema != types.SchemaOf[userModel]("json") || s.tagKey != "json" {
+		fmt.Println("Without configuration, the service should use the cached schema of the model.")
+		t.Fail()
+	}
+}
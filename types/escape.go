@@ -0,0 +1,127 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	c "github.com/pergamenum/go-consensus-standards/constants"
+)
+
+// escapedChars are escaped in the string form of values, as they separate parts of queries and expressions.
+const escapedChars = `\",|()`
+
+// escape backslash-escapes the characters that would otherwise separate the value.
+func escape(s string) string {
+
+	if !strings.ContainsAny(s, escapedChars) {
+		return s
+	}
+
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(escapedChars, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// unescape resolves backslash escapes and removes double quotes.
+func unescape(s string) (string, error) {
+
+	if !strings.ContainsAny(s, `\"`) {
+		return s, nil
+	}
+
+	var sb strings.Builder
+	quoted, escaped := false, false
+	for _, r := range s {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if escaped {
+		return "", fmt.Errorf("(value '%s' ends with an escape)", s)
+	}
+	if quoted {
+		return "", fmt.Errorf("(value '%s' has an unterminated quote)", s)
+	}
+
+	return sb.String(), nil
+}
+
+// indexUnescaped returns the index of the first of the characters in 's' that is neither escaped nor quoted, or -1.
+func indexUnescaped(s string, chars string) int {
+
+	quoted, escaped := false, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case !quoted && strings.ContainsRune(chars, r):
+			return i
+		}
+	}
+
+	return -1
+}
+
+// splitUnescaped splits 's' around every separator that is neither escaped nor quoted. The parts are left escaped.
+func splitUnescaped(s string, sep string) []string {
+
+	var parts []string
+	for {
+		i := indexUnescaped(s, sep)
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+len(sep):]
+	}
+}
+
+// parseList splits the escaped string form of a list value on constants.QueryListSeparator, and unescapes each element.
+func parseList(raw string) ([]any, error) {
+
+	var elements []any
+	for _, part := range splitUnescaped(raw, c.QueryListSeparator) {
+		element, err := unescape(part)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+	}
+
+	return elements, nil
+}
+
+// formatQueryValue is the escaped string form of a value, the inverse of newQuery.
+func formatQueryValue(value any) string {
+
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		elements := make([]string, len(v))
+		for i := range v {
+			elements[i] = escape(formatValue(v[i]))
+		}
+		return strings.Join(elements, c.QueryListSeparator)
+	default:
+		return escape(formatValue(v))
+	}
+}
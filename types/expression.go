@@ -0,0 +1,234 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	r "github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+// Expression is a boolean tree of queries.
+//
+//	A leaf holds a Query and has no Operator.
+//	AND nodes hold any number of children: without any, they match everything.
+//	OR nodes hold one or more children, NOT nodes exactly one.
+type Expression struct {
+	Operator string
+	Query    Query
+	Children []Expression
+}
+
+func Leaf(q Query) Expression {
+	return Expression{Query: q}
+}
+
+func And(children ...Expression) Expression {
+	return Expression{Operator: "AND", Children: children}
+}
+
+func Or(children ...Expression) Expression {
+	return Expression{Operator: "OR", Children: children}
+}
+
+func Not(child Expression) Expression {
+	return Expression{Operator: "NOT", Children: []Expression{child}}
+}
+
+// FromQueries treats a list of queries as what it has always meant: an AND of its queries.
+//
+//	Without queries, it is an AND without children, which matches everything.
+func FromQueries(queries []Query) Expression {
+
+	children := make([]Expression, len(queries))
+	for i, q := range queries {
+		children[i] = Leaf(q)
+	}
+
+	return And(children...)
+}
+
+// IsLeaf reports whether the expression holds a single query.
+func (x Expression) IsLeaf() bool {
+	return x.Operator == ""
+}
+
+// Clone returns a deep copy of the tree, so that it can be validated without altering the original.
+func (x Expression) Clone() Expression {
+
+	if x.Children == nil {
+		return x
+	}
+
+	children := make([]Expression, len(x.Children))
+	for i, child := range x.Children {
+		children[i] = child.Clone()
+	}
+	x.Children = children
+
+	return x
+}
+
+// Validate validates every leaf of the tree, just like Query.Validate, and checks the shape of every node.
+func (x *Expression) Validate(ttt map[string]string, otb map[string]bool) error {
+	return x.ValidateFields(FieldTypes(ttt), otb)
+}
+
+// ValidateFields is like Validate, but validates the leaves with Query.ValidateFields.
+func (x *Expression) ValidateFields(fields map[string]r.FieldType, otb map[string]bool) error {
+	return x.validate(newScope(fields, otb))
+}
+
+func (x *Expression) validate(s scope) error {
+
+	if x == nil {
+		return fmt.Errorf("(expression was nil)")
+	}
+
+	switch x.Operator {
+	case "":
+		if len(x.Children) > 0 {
+			return fmt.Errorf("(invalid expression: a query can not have children)")
+		}
+		return x.Query.validate(s)
+	case "AND":
+	case "OR":
+		if len(x.Children) == 0 {
+			return fmt.Errorf("(invalid expression: 'OR' requires at least one child)")
+		}
+	case "NOT":
+		if len(x.Children) != 1 {
+			return fmt.Errorf("(invalid expression: 'NOT' requires exactly one child, got %d)", len(x.Children))
+		}
+	default:
+		return fmt.Errorf("(invalid expression operator '%s' - valid operators: 'AND OR NOT')", x.Operator)
+	}
+
+	for i := range x.Children {
+		err := x.Children[i].validate(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// String returns the URL form of the expression, e.g. 'and(or(status,EQ,open,status,EQ,pending),owner,EQ,x)'.
+func (x Expression) String() string {
+
+	if x.IsLeaf() {
+		return formatQuery(x.Query)
+	}
+
+	children := make([]string, len(x.Children))
+	for i, child := range x.Children {
+		children[i] = child.String()
+	}
+
+	return strings.ToLower(x.Operator) + "(" + strings.Join(children, ",") + ")"
+}
+
+// FromURL parses the 'filter' parameter into an Expression. Returns nil when there is none.
+//
+//	Other parameters are ignored.
+func (x *Expression) FromURL(input url.Values) (*Expression, error) {
+
+	fs, found := input["filter"]
+	if !found {
+		return nil, nil
+	}
+	if len(fs) > 1 {
+		return nil, e.Wrap("(parameter 'filter' must not be repeated)", e.ErrBadRequest)
+	}
+
+	parsed, err := ParseExpression(fs[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return &parsed, nil
+}
+
+// ParseExpression parses the URL form of an expression, as returned by Expression.String.
+//
+//	expression := group | query
+//	group      := ("and" | "or" | "not") "(" expression { "," expression } ")" | "and()"
+//	query      := <key> "," <operator> "," <value>
+//
+// A query always spans exactly three comma-separated parts, which is what separates it from its siblings.
+// Commas, parentheses and list separators within a part are escaped with a backslash, or enclosed in double quotes.
+func ParseExpression(input string) (Expression, error) {
+
+	x, rest, err := parseExpression(input)
+	if err != nil {
+		return Expression{}, err
+	}
+	if rest != "" {
+		cause := fmt.Sprintf("(invalid filter: unexpected '%s')", rest)
+		return Expression{}, e.Wrap(cause, e.ErrBadRequest)
+	}
+
+	return x, nil
+}
+
+func parseExpression(input string) (Expression, string, error) {
+
+	for _, op := range []string{"AND", "OR", "NOT"} {
+
+		prefix := strings.ToLower(op) + "("
+		if !strings.HasPrefix(strings.ToLower(input), prefix) {
+			continue
+		}
+
+		x := Expression{Operator: op}
+		rest := input[len(prefix):]
+		if op == "AND" && strings.HasPrefix(rest, ")") {
+			return And(), rest[1:], nil
+		}
+		for {
+			child, r, err := parseExpression(rest)
+			if err != nil {
+				return Expression{}, "", err
+			}
+			x.Children = append(x.Children, child)
+
+			if strings.HasPrefix(r, ",") {
+				rest = r[1:]
+				continue
+			}
+			if strings.HasPrefix(r, ")") {
+				return x, r[1:], nil
+			}
+			cause := fmt.Sprintf("(invalid filter: missing ')' after '%s')", child)
+			return Expression{}, "", e.Wrap(cause, e.ErrBadRequest)
+		}
+	}
+
+	// The key and operator end at a comma, the value at a comma or a closing parenthesis.
+	end := 0
+	for part := 0; part < 3; part++ {
+		stop := ","
+		if part == 2 {
+			stop = ",)"
+		}
+		i := indexUnescaped(input[end:], stop)
+		if i < 0 {
+			end = len(input)
+			break
+		}
+		end += i
+		if part < 2 {
+			end++
+		}
+	}
+
+	q, ok := parseQuery(input[:end])
+	if !ok {
+		cause := fmt.Sprintf("(invalid filter: query must be <key>,<operator>,<value>, got '%s')", input[:end])
+		return Expression{}, "", e.Wrap(cause, e.ErrBadRequest)
+	}
+
+	return Leaf(q), input[end:], nil
+}
@@ -0,0 +1,177 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/pergamenum/go-consensus-standards/constants"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+func ExampleParseExpression() {
+
+	type Ticket struct {
+		Status string `json:"status"`
+		Owner  string `json:"owner"`
+	}
+
+	ttt := reflection.MapTagToType("json", Ticket{})
+	otb := constants.ValidOperators
+
+	x := And(
+		Or(
+			Leaf(Query{Key: "status", Operator: "EQ", Value: "open"}),
+			Leaf(Query{Key: "status", Operator: "EQ", Value: "pending"}),
+		),
+		Leaf(Query{Key: "owner", Operator: "EQ", Value: "x"}),
+	)
+	fmt.Println("1:", x)
+
+	parsed, err := ParseExpression(x.String())
+	if err != nil {
+		// Handle error...
+	}
+	fmt.Println("2:", parsed.String() == x.String())
+	fmt.Println("3:", parsed.Validate(ttt, otb))
+
+	// Output:
+	// 1: and(or(status,EQ,open,status,EQ,pending),owner,EQ,x)
+	// 2: true
+	// 3: <nil>
+}
+
+func Test_Expression_Parse(t *testing.T) {
+
+	valid := []string{
+		"age,GE,30",
+		"not(age,GE,30)",
+		"OR(age,LT,10,not(and(age,GT,20,age,LT,30)))",
+		"and(status,IN,a|b,name,ISNULL,)",
+	}
+
+	for _, s := range valid {
+		x, err := ParseExpression(s)
+		if err != nil {
+			fmt.Println(s, err)
+			t.Fail()
+			continue
+		}
+		again, _ := ParseExpression(x.String())
+		if again.String() != x.String() {
+			fmt.Printf("%s did not round-trip: %s -> %s\n", s, x, again)
+			t.Fail()
+		}
+	}
+
+	invalid := []string{
+		"",
+		"age,GE",
+		"and(age,GE,30",
+		"and(age,GE,30))",
+		"and(age,GE,30,)",
+		"not(age,GE,30)x",
+	}
+
+	for _, s := range invalid {
+		if _, err := ParseExpression(s); err == nil {
+			fmt.Printf("'%s' should not parse\n", s)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Expression_Escaping(t *testing.T) {
+
+	x := And(
+		Leaf(Query{Key: "name", Operator: "EQ", Value: "a,b"}),
+		Or(
+			Leaf(Query{Key: "note", Operator: "EQ", Value: `f(x) "quoted" \ back`}),
+			Leaf(Query{Key: "tag", Operator: "IN", Value: []any{"a|b", "c)"}}),
+		),
+	)
+
+	parsed, err := ParseExpression(x.String())
+	if err != nil {
+		fmt.Println(x, err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(parsed, x) {
+		fmt.Printf("%s did not round-trip: %#v\n", x, parsed)
+		t.Fail()
+	}
+
+	parsed, err = ParseExpression(`or(name,EQ,"Smith, John",name,EQ,a\,b)`)
+	if err != nil || parsed.Children[0].Query.Value != "Smith, John" || parsed.Children[1].Query.Value != "a,b" {
+		fmt.Println("quoted and escaped values should parse, got:", parsed, err)
+		t.Fail()
+	}
+
+	for _, s := range []string{`name,EQ,a\`, `name,EQ,"open`} {
+		if _, err := ParseExpression(s); err == nil {
+			fmt.Printf("'%s' should not parse\n", s)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Expression_Validate(t *testing.T) {
+
+	ttt := map[string]string{"age": "int", "name": "string"}
+	otb := constants.ValidOperators
+
+	x := Or(Leaf(Query{Key: "age", Operator: "GE", Value: "30"}), Not(Leaf(Query{Key: "name", Operator: "EQ", Value: "x"})))
+	clone := x.Clone()
+	err := x.Validate(ttt, otb)
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	if x.Children[0].Query.Value != 30 {
+		fmt.Println("Validate should convert the values of leaves, got:", x.Children[0].Query.Value)
+		t.Fail()
+	}
+	if clone.Children[0].Query.Value != "30" {
+		fmt.Println("Validate should not alter a clone.")
+		t.Fail()
+	}
+
+	empty := FromQueries([]Query{})
+	if err := empty.Validate(ttt, otb); err != nil {
+		fmt.Println("An AND without children should validate, got:", err)
+		t.Fail()
+	}
+	if parsed, err := ParseExpression(empty.String()); err != nil || parsed.Operator != "AND" || len(parsed.Children) != 0 {
+		fmt.Println("An AND without children should round-trip, got:", parsed, err)
+		t.Fail()
+	}
+
+	invalid := []Expression{
+		Or(),
+		{Operator: "NOT", Children: []Expression{x, x}},
+		{Operator: "XOR", Children: []Expression{x}},
+		And(Leaf(Query{Key: "nope", Operator: "EQ", Value: "1"})),
+		Not(Leaf(Query{Key: "age", Operator: "EQ", Value: "old"})),
+	}
+
+	for _, x := range invalid {
+		if err := x.Validate(ttt, otb); err == nil {
+			fmt.Printf("%v should not validate\n", x)
+			t.Fail()
+		}
+	}
+
+	var q Query
+	input, _ := url.ParseQuery("q=age,GE,1&filter=or(name,EQ,a,name,EQ,b)&limit=5")
+	if _, err := q.FromURL(input); err != nil {
+		fmt.Println("Query.FromURL should tolerate a filter, got:", err)
+		t.Fail()
+	}
+	var o SearchOptions
+	opts, err := o.FromURL(input)
+	if err != nil || opts.Filter == nil || opts.Filter.Operator != "OR" {
+		fmt.Println("SearchOptions.FromURL should parse the filter, got:", opts.Filter, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,379 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	c "github.com/pergamenum/go-consensus-standards/constants"
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+)
+
+// FilterError describes where, and why, a filter failed to parse.
+//
+//	It unwraps to ehandler.ErrBadRequest.
+type FilterError struct {
+	// Pos is the byte offset in the input, starting at 0.
+	Pos     int
+	Problem string
+}
+
+func (fe *FilterError) Error() string {
+	return fmt.Sprintf("(invalid filter at position %d: %s)", fe.Pos, fe.Problem)
+}
+
+func (fe *FilterError) Unwrap() error {
+	return e.ErrBadRequest
+}
+
+// ParseFilter parses the textual filter language into an expression, such as:
+//
+//	age ge 30 and (status eq 'open' or status in ('new', 'pending')) and not deleted notnull
+//
+// The grammar, where keywords and operators ignore case and 'and' binds tighter than 'or':
+//
+//	filter     := term { "or" term }
+//	term       := factor { "and" factor }
+//	factor     := "not" factor | "(" filter ")" | query
+//	query      := <key> <operator> [ value | "(" value { "," value } ")" ]
+//	value      := "'" <text, with '' for '> "'" | <word>
+//
+// Words are any run of characters other than whitespace, parentheses, commas and quotes, such as 30, true or now-24h.
+// Values are left as strings, to be converted by validation. Null operators take no value, set and range operators a list.
+func ParseFilter(input string) (Expression, error) {
+
+	tokens, err := lexFilter(input)
+	if err != nil {
+		return Expression{}, err
+	}
+
+	p := &filterParser{tokens: tokens}
+	x, err := p.filter()
+	if err != nil {
+		return Expression{}, err
+	}
+	if t := p.peek(); t.kind != tokenEnd {
+		return Expression{}, p.fail(t, "expected 'and', 'or' or the end of the filter")
+	}
+
+	return x, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOpen
+	tokenClose
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+
+	switch t.kind {
+	case tokenEnd:
+		return "the end of the filter"
+	case tokenString:
+		return fmt.Sprintf("'%s'", strings.ReplaceAll(t.text, "'", "''"))
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+func lexFilter(input string) ([]token, error) {
+
+	var tokens []token
+	for i := 0; i < len(input); {
+
+		ch := input[i]
+		switch {
+		case isSpace(ch):
+			i++
+
+		case ch == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "(", pos: i})
+			i++
+
+		case ch == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")", pos: i})
+			i++
+
+		case ch == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+
+		case ch == '\'':
+			var sb strings.Builder
+			start := i
+			i++
+			for {
+				if i >= len(input) {
+					return nil, &FilterError{Pos: start, Problem: "unterminated string"}
+				}
+				if input[i] == '\'' {
+					// A doubled quote stands for a single one.
+					if i+1 < len(input) && input[i+1] == '\'' {
+						sb.WriteByte('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				sb.WriteByte(input[i])
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+
+		default:
+			start := i
+			for i < len(input) && !isSpace(input[i]) && strings.IndexByte("(),'", input[i]) < 0 {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: input[start:i], pos: start})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd, pos: len(input)}), nil
+}
+
+// isSpace reports whether the byte is ASCII whitespace. Bytes of multibyte characters never are.
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+type filterParser struct {
+	tokens []token
+	next   int
+}
+
+func (p *filterParser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *filterParser) take() token {
+
+	t := p.tokens[p.next]
+	if t.kind != tokenEnd {
+		p.next++
+	}
+
+	return t
+}
+
+// keyword reports whether the next token is the keyword, and takes it if so.
+func (p *filterParser) keyword(k string) bool {
+
+	t := p.peek()
+	if t.kind == tokenWord && strings.EqualFold(t.text, k) {
+		p.next++
+		return true
+	}
+
+	return false
+}
+
+func (p *filterParser) fail(t token, problem string) error {
+	return &FilterError{Pos: t.pos, Problem: fmt.Sprintf("%s, got %s", problem, t)}
+}
+
+func (p *filterParser) filter() (Expression, error) {
+
+	var children []Expression
+	for {
+		x, err := p.term()
+		if err != nil {
+			return Expression{}, err
+		}
+		children = append(children, x)
+		if !p.keyword("or") {
+			break
+		}
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return Or(children...), nil
+}
+
+func (p *filterParser) term() (Expression, error) {
+
+	var children []Expression
+	for {
+		x, err := p.factor()
+		if err != nil {
+			return Expression{}, err
+		}
+		children = append(children, x)
+		if !p.keyword("and") {
+			break
+		}
+	}
+
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return And(children...), nil
+}
+
+func (p *filterParser) factor() (Expression, error) {
+
+	if p.keyword("not") {
+		x, err := p.factor()
+		if err != nil {
+			return Expression{}, err
+		}
+		return Not(x), nil
+	}
+
+	if p.peek().kind == tokenOpen {
+		p.take()
+		x, err := p.filter()
+		if err != nil {
+			return Expression{}, err
+		}
+		if t := p.take(); t.kind != tokenClose {
+			return Expression{}, p.fail(t, "expected ')'")
+		}
+		return x, nil
+	}
+
+	return p.query()
+}
+
+func (p *filterParser) query() (Expression, error) {
+
+	key := p.take()
+	if key.kind != tokenWord {
+		return Expression{}, p.fail(key, "expected a key, 'not' or '('")
+	}
+
+	opToken := p.take()
+	op := strings.ToUpper(opToken.text)
+	if opToken.kind != tokenWord || !c.ValidOperators[op] {
+		return Expression{}, p.fail(opToken, "expected an operator")
+	}
+
+	q := Query{Key: key.text, Operator: op}
+	switch {
+
+	case c.ValidNullOperators[op]:
+
+	case c.ValidSetOperators[op] || c.ValidRangeOperators[op]:
+		if t := p.take(); t.kind != tokenOpen {
+			return Expression{}, p.fail(t, fmt.Sprintf("expected '(' to start the list of '%s'", strings.ToLower(op)))
+		}
+		var elements []any
+		for {
+			v, err := p.value()
+			if err != nil {
+				return Expression{}, err
+			}
+			elements = append(elements, v)
+
+			t := p.take()
+			if t.kind == tokenClose {
+				break
+			}
+			if t.kind != tokenComma {
+				return Expression{}, p.fail(t, "expected ',' or ')'")
+			}
+		}
+		q.Value = elements
+
+	default:
+		v, err := p.value()
+		if err != nil {
+			return Expression{}, err
+		}
+		q.Value = v
+	}
+
+	return Leaf(q), nil
+}
+
+func (p *filterParser) value() (string, error) {
+
+	t := p.take()
+	if t.kind != tokenWord && t.kind != tokenString {
+		return "", p.fail(t, "expected a value")
+	}
+
+	return t.text, nil
+}
+
+// FormatFilter writes the expression in the canonical form of the filter language, which ParseFilter reads back.
+//
+//	Keywords and operators are lower case, strings and times are quoted, and parentheses are only used where needed.
+func FormatFilter(x Expression) string {
+	return formatFilter(x, "")
+}
+
+// formatFilter formats the expression as a child of a node with the operator 'parent'.
+func formatFilter(x Expression, parent string) string {
+
+	if x.IsLeaf() {
+		return formatFilterQuery(x.Query)
+	}
+
+	if x.Operator == "NOT" && len(x.Children) == 1 {
+		return "not " + formatFilter(x.Children[0], x.Operator)
+	}
+
+	children := make([]string, len(x.Children))
+	for i, child := range x.Children {
+		children[i] = formatFilter(child, x.Operator)
+	}
+	s := strings.Join(children, " "+strings.ToLower(x.Operator)+" ")
+
+	// An OR within an AND, and any group within a NOT, needs parentheses.
+	if len(x.Children) > 1 && (parent == "NOT" || (parent == "AND" && x.Operator == "OR")) {
+		return "(" + s + ")"
+	}
+
+	return s
+}
+
+func formatFilterQuery(q Query) string {
+
+	s := q.Key + " " + strings.ToLower(q.Operator)
+	switch v := q.Value.(type) {
+	case nil:
+		return s
+	case []any:
+		elements := make([]string, len(v))
+		for i := range v {
+			elements[i] = formatFilterValue(v[i])
+		}
+		return s + " (" + strings.Join(elements, ", ") + ")"
+	default:
+		return s + " " + formatFilterValue(v)
+	}
+}
+
+func formatFilterValue(v any) string {
+
+	switch v := v.(type) {
+	case Ref:
+		return v.String()
+	case time.Time:
+		return "'" + Times.Format(v) + "'"
+	}
+
+	// Including named string types, such as 'type Status string'.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.String {
+		return "'" + strings.ReplaceAll(rv.String(), "'", "''") + "'"
+	}
+
+	return fmt.Sprint(v)
+}
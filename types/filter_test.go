@@ -0,0 +1,105 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+)
+
+func ExampleParseFilter() {
+
+	x, err := ParseFilter("age GE 30 and (status eq 'open' or status in ('new', 'it''s')) and not deleted notnull")
+	if err != nil {
+		// Handle error...
+	}
+
+	fmt.Println(x.Operator, len(x.Children))
+	fmt.Println(FormatFilter(x))
+
+	// Output:
+	// AND 3
+	// age ge '30' and (status eq 'open' or status in ('new', 'it''s')) and not deleted notnull
+}
+
+func Test_ParseFilter(t *testing.T) {
+
+	tests := []struct {
+		input string
+		want  Expression
+	}{
+		{
+			input: "name eq Jeff",
+			want:  Leaf(Query{Key: "name", Operator: "EQ", Value: "Jeff"}),
+		},
+		{
+			input: "a eq 1 or b eq 2 and c eq 3",
+			want: Or(
+				Leaf(Query{Key: "a", Operator: "EQ", Value: "1"}),
+				And(
+					Leaf(Query{Key: "b", Operator: "EQ", Value: "2"}),
+					Leaf(Query{Key: "c", Operator: "EQ", Value: "3"}),
+				),
+			),
+		},
+		{
+			input: "not (a isnull or updated gt @created)",
+			want: Not(Or(
+				Leaf(Query{Key: "a", Operator: "ISNULL"}),
+				Leaf(Query{Key: "updated", Operator: "GT", Value: "@created"}),
+			)),
+		},
+		{
+			input: "created between (today-7d, now) and city eq 'Tromsø'",
+			want: And(
+				Leaf(Query{Key: "created", Operator: "BETWEEN", Value: []any{"today-7d", "now"}}),
+				Leaf(Query{Key: "city", Operator: "EQ", Value: "Tromsø"}),
+			),
+		},
+	}
+
+	for _, test := range tests {
+		got, err := ParseFilter(test.input)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			fmt.Printf("%s: got %v (%v), want %v\n", test.input, got, err, test.want)
+			t.Fail()
+			continue
+		}
+
+		// The canonical form parses back to the same expression.
+		again, err := ParseFilter(FormatFilter(got))
+		if err != nil || !reflect.DeepEqual(again, test.want) {
+			fmt.Printf("%s: failed to round trip through '%s'\n", test.input, FormatFilter(got))
+			t.Fail()
+		}
+	}
+}
+
+func Test_ParseFilter_Errors(t *testing.T) {
+
+	tests := []struct {
+		input string
+		pos   int
+	}{
+		{input: "age", pos: 3},
+		{input: "age older 30", pos: 4},
+		{input: "age eq 30 xor b eq 1", pos: 10},
+		{input: "(age eq 30", pos: 10},
+		{input: "name eq 'Jeff", pos: 8},
+		{input: "age in 1, 2", pos: 7},
+		{input: "age in (1 2)", pos: 10},
+		{input: "", pos: 0},
+	}
+
+	for _, test := range tests {
+		_, err := ParseFilter(test.input)
+
+		var fe *FilterError
+		if !errors.As(err, &fe) || fe.Pos != test.pos || !errors.Is(err, e.ErrBadRequest) {
+			fmt.Printf("%q: expected an error at %d, got: %v\n", test.input, test.pos, err)
+			t.Fail()
+		}
+	}
+}
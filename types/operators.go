@@ -0,0 +1,63 @@
+package types
+
+import (
+	c "github.com/pergamenum/go-consensus-standards/constants"
+	r "github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+// Operators permitted by default, by the kind of field.
+var (
+	orderedOperators = union(
+		c.ValidRelationalOperators,
+		c.ValidSetOperators,
+		c.ValidRangeOperators,
+		c.ValidNullOperators,
+	)
+	stringOperators = union(
+		c.ValidEqualityOperators,
+		c.ValidSetOperators,
+		c.ValidStringOperators,
+		c.ValidNullOperators,
+	)
+	unorderedOperators = union(
+		c.ValidEqualityOperators,
+		c.ValidSetOperators,
+		c.ValidNullOperators,
+	)
+)
+
+// PermittedOperators returns the operators permitted on a field of the type, before any global restriction.
+//
+// These are the operators of its 'query' tag, if any. Otherwise, they depend on the kind of the field,
+// or of its elements: ordering and range operators only apply to numbers and times, string operators only to strings.
+func PermittedOperators(ft r.FieldType) map[string]bool {
+
+	if ft.Operators != nil {
+		m := map[string]bool{}
+		for _, op := range ft.Operators {
+			m[op] = true
+		}
+		return m
+	}
+
+	switch ft.Scalar().Base {
+	case "string":
+		return stringOperators
+	case "bool", "complex64", "complex128", "":
+		return unorderedOperators
+	default:
+		return orderedOperators
+	}
+}
+
+func union(ms ...map[string]bool) map[string]bool {
+
+	u := map[string]bool{}
+	for _, m := range ms {
+		for k, v := range m {
+			u[k] = v
+		}
+	}
+
+	return u
+}
@@ -3,12 +3,15 @@ package types
 import (
 	"fmt"
 	"net/url"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	c "github.com/pergamenum/go-consensus-standards/constants"
 	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	r "github.com/pergamenum/go-consensus-standards/reflection"
 )
 
 type Query struct {
@@ -17,36 +20,80 @@ type Query struct {
 	Value    any
 }
 
+// Validate checks the query against a map of tags to type names, as returned by reflection.MapTagToType,
+// and converts the value to the type of the field.
 func (q *Query) Validate(ttt map[string]string, otb map[string]bool) error {
+	return q.ValidateFields(FieldTypes(ttt), otb)
+}
+
+// ValidateFields checks the query against a map of tags to field types, as returned by reflection.MapTagToFieldType,
+// and converts the value to the type of the field.
+//
+//	Values of slice and array fields are converted to the element type.
+func (q *Query) ValidateFields(fields map[string]r.FieldType, otb map[string]bool) error {
+
+	return q.validate(newScope(fields, otb))
+}
+
+func (q *Query) validate(s scope) error {
 
 	if q == nil {
 		return fmt.Errorf("(query was nil)")
 	}
 
+	problem := q.problem(s)
+	if problem != "" {
+		cause := fmt.Sprintf("(invalid query: %v)", problem)
+		return fmt.Errorf(cause)
+	}
+
+	return nil
+}
+
+// scope is what queries are validated against.
+type scope struct {
+	fields map[string]r.FieldType
+	otb    map[string]bool
+	// now resolves relative times.
+	now   func() time.Time
+	times TimeConfig
+}
+
+// newScope validates against the fields and operators, with the package's clock and time configuration.
+func newScope(fields map[string]r.FieldType, otb map[string]bool) scope {
+	return scope{fields: fields, otb: otb, now: Now, times: Times}
+}
+
+// problem validates and converts the query, returning what is wrong with it, if anything.
+func (q *Query) problem(s scope) string {
+
+	fields, otb := s.fields, s.otb
+
 	var sb strings.Builder
 	// Validate Key and Value.
-	if t, found := ttt[q.Key]; !found {
+	if ft, found := fields[q.Key]; !found {
 		var vks []string
-		for vk := range ttt {
+		for vk := range fields {
 			vks = append(vks, vk)
 		}
 		sb.WriteString(fmt.Sprintf("(invalid key '%v' ", q.Key))
 		sb.WriteString(fmt.Sprintf("- valid keys: '%v')", strings.Join(vks, " ")))
-	} else {
-		actuallyString := t == "string"
-		_, foundString := q.Value.(string)
-		// When the 'Value any' field holds a string representation of another type.
-		if foundString && !actuallyString {
-			err := q.setValueFromString(t)
-			if err != nil {
-				sb.WriteString(err.Error())
-			}
-		} else {
-			err := AssertAny(&q.Value, t)
-			if err != nil {
-				sb.WriteString(err.Error())
+	} else if permitted := PermittedOperators(ft); otb[q.Operator] && !permitted[q.Operator] {
+		// The value is left alone, as its form depends on the operator.
+		var pos []string
+		for po, v := range otb {
+			if v && permitted[po] {
+				pos = append(pos, po)
 			}
 		}
+		sort.Strings(pos)
+		sb.WriteString(fmt.Sprintf("(operator '%v' is not permitted on '%v' ", q.Operator, q.Key))
+		sb.WriteString(fmt.Sprintf("- permitted operators: '%v')", strings.Join(pos, " ")))
+	} else {
+		err := q.validateValue(s, ft)
+		if err != nil {
+			sb.WriteString(err.Error())
+		}
 	}
 	// Validate Operator.
 	if valid, found := otb[q.Operator]; !found || !valid {
@@ -60,15 +107,143 @@ func (q *Query) Validate(ttt map[string]string, otb map[string]bool) error {
 		sb.WriteString(fmt.Sprintf("- valid operators: '%v')", strings.Join(vos, " ")))
 	}
 
-	if len(sb.String()) > 0 {
-		cause := fmt.Sprintf("(invalid query: %v)", strings.TrimSpace(sb.String()))
-		return fmt.Errorf(cause)
+	return strings.TrimSpace(sb.String())
+}
+
+// FieldTypes converts a map of tags to type names, as returned by reflection.MapTagToType, to field types.
+func FieldTypes(ttt map[string]string) map[string]r.FieldType {
+
+	fields := make(map[string]r.FieldType, len(ttt))
+	for k, name := range ttt {
+		fields[k] = r.TypeFromName(name)
 	}
 
-	return nil
+	return fields
+}
+
+// validateValue converts the value to the field type, according to the form the operator expects.
+func (q *Query) validateValue(s scope, ft r.FieldType) error {
+
+	scalar := ft.Scalar()
+
+	switch {
+
+	case c.ValidNullOperators[q.Operator]:
+		q.Value = nil
+		return nil
+
+	case c.ValidStringOperators[q.Operator]:
+		if scalar.Base != "string" {
+			return fmt.Errorf("(operator '%s' requires a string field, got '%s')", q.Operator, ft)
+		}
+		// Kept as a plain string, as it is matched against part of the field.
+		return AssertAny(&q.Value, "string")
+
+	case c.ValidSetOperators[q.Operator] || c.ValidRangeOperators[q.Operator]:
+		elements, err := toList(q.Value)
+		if err != nil {
+			return err
+		}
+		if len(elements) == 0 {
+			return fmt.Errorf("(operator '%s' requires at least one element)", q.Operator)
+		}
+		if c.ValidRangeOperators[q.Operator] && len(elements) != 2 {
+			return fmt.Errorf("(operator '%s' requires exactly two elements, got %d)", q.Operator, len(elements))
+		}
+		for i := range elements {
+			element := Query{Value: elements[i]}
+			err = element.validateScalar(s, scalar)
+			if err != nil {
+				return err
+			}
+			elements[i] = element.Value
+		}
+		q.Value = elements
+		return nil
+
+	default:
+		return q.validateScalar(s, scalar)
+	}
+}
+
+// validateScalar converts a single value to the type 'ft', parsing it when given as a string.
+//
+//	A string of the form '@key' naming another field is a reference to that field, see Ref.
+func (q *Query) validateScalar(s scope, ft r.FieldType) error {
+
+	if ft.Base == "" {
+		return fmt.Errorf("(unsupported type '%s' with value '%v')", ft, q.Value)
+	}
+	if ft.Type != nil && reflect.TypeOf(q.Value) == ft.Type {
+		return q.validateEnum(ft)
+	}
+	if ref, found := q.reference(s.fields); found {
+		return q.validateRef(s.fields, ft, ref)
+	}
+
+	var err error
+	_, foundString := q.Value.(string)
+	// When the 'Value any' field holds a string representation of another type.
+	if foundString && ft.Base != "string" {
+		err = q.setValueFromString(ft, s)
+	} else {
+		err = AssertAny(&q.Value, ft.Base)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Named types, such as 'type Status string', hold values of their own type.
+	if ft.Named && ft.Type != nil {
+		q.Value = reflect.ValueOf(q.Value).Convert(ft.Type).Interface()
+	}
+
+	return q.validateEnum(ft)
+}
+
+// validateEnum checks the converted value against the allowed values of the type, if restricted.
+func (q *Query) validateEnum(ft r.FieldType) error {
+
+	if len(ft.Enum) == 0 {
+		return nil
+	}
+
+	value := formatValue(q.Value)
+	for _, v := range ft.Enum {
+		if v == value {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("(invalid value '%v' - valid values: '%v')", value, strings.Join(ft.Enum, " "))
+}
+
+// toList returns a copy of a list value, splitting its string form on constants.QueryListSeparator.
+func toList(value any) ([]any, error) {
+
+	switch v := value.(type) {
+	case string:
+		var elements []any
+		for _, s := range strings.Split(v, c.QueryListSeparator) {
+			elements = append(elements, s)
+		}
+		return elements, nil
+	case []string:
+		elements := make([]any, len(v))
+		for i := range v {
+			elements[i] = v[i]
+		}
+		return elements, nil
+	case []any:
+		elements := make([]any, len(v))
+		copy(elements, v)
+		return elements, nil
+	default:
+		return nil, fmt.Errorf("(value '%v' is not a list)", value)
+	}
 }
 
-func (q *Query) setValueFromString(t string) error {
+func (q *Query) setValueFromString(ft r.FieldType, s scope) error {
 
 	var sv string
 	if s, ok := q.Value.(string); !ok {
@@ -79,19 +254,19 @@ func (q *Query) setValueFromString(t string) error {
 	if sv == "" {
 		return fmt.Errorf("(value is empty)")
 	}
-	if t == "" {
+	if ft.Base == "" {
 		return fmt.Errorf("(type parameter is empty)")
 	}
 
 	f := func(result any, err error) error {
 		if err != nil {
-			return fmt.Errorf("(value '%v' is not a valid '%s' - info: (%s))", q.Value, t, err.Error())
+			return fmt.Errorf("(value '%v' is not a valid '%s' - info: (%s))", q.Value, ft.Name, err.Error())
 		}
 		q.Value = result
 		return nil
 	}
 
-	switch strings.ToLower(t) {
+	switch ft.Base {
 
 	case "bool":
 		result, err := strconv.ParseBool(sv)
@@ -164,48 +339,65 @@ func (q *Query) setValueFromString(t string) error {
 		return f(result, err)
 
 	case "time":
-		result, err := time.Parse(c.QueryTimeFormat, sv)
+		if result, found, err := parseRelativeTime(sv, s.now().In(s.times.location())); found {
+			return f(result, err)
+		}
+		result, err := s.times.Parse(sv)
 		if err != nil {
-			cause := fmt.Errorf("(valid form: '%s')", c.QueryTimeHint)
+			cause := fmt.Errorf("(valid forms: %s or %s)", s.times.Hint(), c.QueryRelativeTimeHint)
 			err = e.Wrap(cause, err)
 		}
 		return f(result, err)
 
 	default:
-		return fmt.Errorf("(unsupported type '%s' with value '%v')", t, q.Value)
+		return fmt.Errorf("(unsupported type '%s' with value '%v')", ft, q.Value)
 	}
 }
 
+// FromURL parses the 'q=<key>,<operator>,<value>' parameters into queries, see CommaSyntax.
+//
+//	Parameters of SearchOptions may coexist with queries, any other parameter is rejected.
 func (q *Query) FromURL(input url.Values) ([]Query, error) {
+	return CommaSyntax{}.Decode(input)
+}
 
-	if len(input) == 0 {
-		return []Query{}, nil
+// parseQuery parses the '<key>,<operator>,<value>' form of a query.
+//
+//	Separators within a part are escaped with a backslash, or enclosed in double quotes.
+func parseQuery(qs string) (Query, bool) {
+
+	split := splitUnescaped(qs, ",")
+	if len(split) != 3 {
+		return Query{}, false
 	}
 
-	cause := "(query must be q=<key>,<operator>,<value>)"
-	err := e.Wrap(cause, e.ErrBadRequest)
+	key, err := unescape(split[0])
+	if err != nil {
+		return Query{}, false
+	}
+	op, err := unescape(split[1])
+	if err != nil {
+		return Query{}, false
+	}
 
-	// Ensure that any non-conforming query is reported back as invalid.
-	qss, found := input["q"]
-	if !found || len(input) > 1 {
-		return nil, err
+	query, err := newQuery(key, op, split[2])
+	if err != nil {
+		return Query{}, false
 	}
 
-	var queries []Query
-	for _, qs := range qss {
+	return query, true
+}
 
-		split := strings.Split(qs, ",")
-		if len(split) != 3 {
-			return nil, err
-		}
+// formatQuery is the inverse of parseQuery.
+func formatQuery(q Query) string {
+	return escape(q.Key) + "," + escape(q.Operator) + "," + formatQueryValue(q.Value)
+}
 
-		query := Query{
-			Key:      split[0],
-			Operator: split[1],
-			Value:    split[2],
-		}
-		queries = append(queries, query)
+func formatValue(v any) string {
+
+	if t, ok := v.(time.Time); ok {
+		return Times.Format(t)
 	}
 
-	return queries, nil
+	return fmt.Sprint(v)
 }
@@ -2,6 +2,9 @@ package types
 
 import (
 	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
 
 	"github.com/pergamenum/go-consensus-standards/constants"
 	"github.com/pergamenum/go-consensus-standards/reflection"
@@ -32,3 +35,149 @@ func ExampleQuery_Validate() {
 	// Output:
 	// 2: Value Type: int
 }
+
+func ExampleQuery_Validate_list() {
+
+	type Ticket struct {
+		Status string `json:"status"`
+		Points int    `json:"points"`
+	}
+
+	ttt := reflection.MapTagToType("json", Ticket{})
+	otb := constants.ValidOperators
+
+	input, _ := url.ParseQuery("q=status,IN,open|pending&q=points,BETWEEN,1|8")
+
+	var q Query
+	queries, err := q.FromURL(input)
+	if err != nil {
+		// Handle error...
+	}
+
+	for i := range queries {
+		err = queries[i].Validate(ttt, otb)
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		fmt.Printf("%s %s %#v\n", queries[i].Key, queries[i].Operator, queries[i].Value)
+	}
+
+	bad := Query{Key: "points", Operator: "CONTAINS", Value: "1"}
+	fmt.Println(bad.Validate(ttt, otb))
+
+	// Output:
+	// status IN []interface {}{"open", "pending"}
+	// points BETWEEN []interface {}{1, 8}
+	// (invalid query: (operator 'CONTAINS' is not permitted on 'points' - permitted operators: 'BETWEEN EQ GE GT IN ISNULL LE LT NE NIN NOTNULL'))
+}
+
+func ExampleQuery_Validate_nested() {
+
+	type Address struct {
+		Zip int `json:"zip"`
+	}
+	type User struct {
+		Address Address `json:"address"`
+	}
+
+	ttt := reflection.MapTagToType("json", User{})
+	otb := constants.ValidRelationalOperators
+
+	q := Query{Key: "address.zip", Operator: "GE", Value: "5000"}
+
+	err := q.Validate(ttt, otb)
+	if err != nil {
+		fmt.Println("1: Error:", err)
+	} else {
+		fmt.Printf("2: Value Type: %T", q.Value)
+	}
+	// Output:
+	// 2: Value Type: int
+}
+
+func Test_Query_ValidateFields(t *testing.T) {
+
+	type Status string
+	type Ticket struct {
+		Status   Status   `json:"status"`
+		Assignee *int     `json:"assignee"`
+		Labels   []string `json:"labels"`
+		Owners   []int    `json:"owners"`
+	}
+
+	fields := reflection.MapTagToFieldType("json", Ticket{})
+	otb := constants.ValidOperators
+
+	tests := []struct {
+		query Query
+		want  any
+	}{
+		{query: Query{Key: "status", Operator: "EQ", Value: "open"}, want: Status("open")},
+		{query: Query{Key: "status", Operator: "IN", Value: "open|closed"}, want: []any{Status("open"), Status("closed")}},
+		{query: Query{Key: "status", Operator: "PREFIX", Value: "op"}, want: "op"},
+		{query: Query{Key: "assignee", Operator: "GE", Value: "7"}, want: 7},
+		{query: Query{Key: "labels", Operator: "EQ", Value: "bug"}, want: "bug"},
+		{query: Query{Key: "owners", Operator: "IN", Value: "1|2"}, want: []any{1, 2}},
+	}
+
+	for _, tc := range tests {
+		q := tc.query
+		err := q.ValidateFields(fields, otb)
+		if err != nil {
+			fmt.Println(err)
+			t.Fail()
+			continue
+		}
+		if !reflect.DeepEqual(q.Value, tc.want) {
+			fmt.Printf("%v: got %#v, want %#v\n", tc.query, q.Value, tc.want)
+			t.Fail()
+		}
+	}
+
+	q := Query{Key: "owners", Operator: "CONTAINS", Value: "1"}
+	if err := q.ValidateFields(fields, otb); err == nil {
+		fmt.Println("String operators should be rejected on fields of numbers.")
+		t.Fail()
+	}
+}
+
+func ExampleQuery_ValidateFields_enum() {
+
+	type Ticket struct {
+		State string `json:"state" enum:"open,closed"`
+	}
+
+	fields := reflection.MapTagToFieldType("json", Ticket{})
+	otb := constants.ValidOperators
+
+	q := Query{Key: "state", Operator: "IN", Value: "open|pending"}
+
+	err := q.ValidateFields(fields, otb)
+	fmt.Println(err)
+
+	// Output:
+	// (invalid query: (invalid value 'pending' - valid values: 'open closed'))
+}
+
+func ExampleQuery_ValidateFields_operators() {
+
+	type Ticket struct {
+		ID     string `json:"id" query:"id,ops=EQ|IN"`
+		Active bool   `json:"active"`
+	}
+
+	fields := reflection.MapTagToFieldType("json", Ticket{})
+	otb := constants.ValidOperators
+
+	for _, q := range []Query{
+		{Key: "id", Operator: "GT", Value: "7"},
+		{Key: "active", Operator: "LT", Value: "true"},
+	} {
+		fmt.Println(q.ValidateFields(fields, otb))
+	}
+
+	// Output:
+	// (invalid query: (operator 'GT' is not permitted on 'id' - permitted operators: 'EQ IN'))
+	// (invalid query: (operator 'LT' is not permitted on 'active' - permitted operators: 'EQ IN ISNULL NE NIN NOTNULL'))
+}
@@ -0,0 +1,400 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	c "github.com/pergamenum/go-consensus-standards/constants"
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	r "github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+// Schema describes how a model type may be searched: its queryable fields and their types, the operators
+// permitted on each, key aliases, the default sort and the maximum page size.
+//
+// Build it once per model type, with SchemaOf or NewSchema, and share it. It is safe for concurrent use.
+type Schema struct {
+	tagKey    string
+	fields    map[string]r.FieldType
+	operators map[string]bool
+	// aliases maps alternative keys to the keys of fields.
+	aliases     map[string]string
+	defaultSort []Sort
+	maxLimit    int
+	syntax      URLSyntax
+	times       TimeConfig
+	now         func() time.Time
+	// err is set on schemas returned by SchemaOf and DefaultSchema when building failed.
+	err error
+}
+
+type SchemaConfig struct {
+	// TagKey is the struct tag key naming the fields. Defaults to "json".
+	TagKey string
+	// Operators are the operators allowed in queries, before any per-field restriction. Defaults to constants.ValidOperators.
+	Operators map[string]bool
+	// Aliases maps alternative keys, accepted in queries and sort keys, to the keys of fields.
+	Aliases map[string]string
+	// DefaultSort applies to searches without sort keys of their own.
+	DefaultSort []Sort
+	// MaxLimit caps the number of items in a page of search results. Defaults to constants.MaxSearchLimit.
+	MaxLimit int
+	// Syntax reads queries from URLs. Defaults to CommaSyntax.
+	Syntax URLSyntax
+	// Times parses time values. Defaults to a copy of the package's Times, taken when the schema is built.
+	Times *TimeConfig
+	// Now resolves relative times. Defaults to the package's Now, taken when the schema is built.
+	Now func() time.Time
+}
+
+// NewSchema builds the schema of the model type M.
+//
+//	Fails when an alias or a default sort key does not name a field, or an alias hides one,
+//	when a 'query' tag of the model lists an unknown operator,
+//	and, with ColonSyntax, when a key or alias is one of constants.SearchOptionKeys, unless an alias stands for the key.
+func NewSchema[M any](conf SchemaConfig) (*Schema, error) {
+
+	tagKey := conf.TagKey
+	if tagKey == "" {
+		tagKey = "json"
+	}
+	operators := conf.Operators
+	if operators == nil {
+		operators = c.ValidOperators
+	}
+	maxLimit := conf.MaxLimit
+	if maxLimit <= 0 {
+		maxLimit = c.MaxSearchLimit
+	}
+	syntax := conf.Syntax
+	if syntax == nil {
+		syntax = CommaSyntax{}
+	}
+	// The package's defaults are copied, so that validations never read them while they may be replaced.
+	times := Times
+	if conf.Times != nil {
+		times = *conf.Times
+	}
+	times.Layouts = append([]string{}, times.Layouts...)
+	now := conf.Now
+	if now == nil {
+		now = Now
+	}
+
+	var model M
+	fields, err := r.DescribeFields(tagKey, model)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schema{
+		tagKey:      tagKey,
+		fields:      fields,
+		operators:   operators,
+		aliases:     map[string]string{},
+		defaultSort: append([]Sort{}, conf.DefaultSort...),
+		maxLimit:    maxLimit,
+		syntax:      syntax,
+		times:       times,
+		now:         now,
+	}
+
+	for alias, key := range conf.Aliases {
+		if _, found := s.fields[key]; !found {
+			return nil, fmt.Errorf("(invalid alias '%s': no such key '%s')", alias, key)
+		}
+		if _, found := s.fields[alias]; found {
+			return nil, fmt.Errorf("(invalid alias '%s': hides a key of the same name)", alias)
+		}
+		s.aliases[alias] = key
+	}
+	if _, colon := syntax.(ColonSyntax); colon {
+		if err := s.reservedKeys(); err != nil {
+			return nil, err
+		}
+	}
+	for i, sort := range s.defaultSort {
+		s.defaultSort[i].Key = s.resolve(sort.Key)
+		if _, found := s.fields[s.defaultSort[i].Key]; !found {
+			return nil, fmt.Errorf("(invalid default sort key '%s')", sort.Key)
+		}
+	}
+
+	return s, nil
+}
+
+type schemaKey struct {
+	t      reflect.Type
+	tagKey string
+}
+
+// schemas caches the schemas returned by SchemaOf.
+var schemas sync.Map
+
+// SchemaOf returns the schema of the model type M with the default configuration and the given tag key.
+//
+//	It is built on first use, and cached for the lifetime of the program.
+//	When the model's tags are invalid, every validation fails with an internal error, see Err.
+func SchemaOf[M any](tagKey string) *Schema {
+
+	if tagKey == "" {
+		tagKey = "json"
+	}
+	key := schemaKey{t: reflect.TypeOf((*M)(nil)).Elem(), tagKey: tagKey}
+	if s, found := schemas.Load(key); found {
+		return s.(*Schema)
+	}
+
+	actual, _ := schemas.LoadOrStore(key, buildSchema[M](SchemaConfig{TagKey: tagKey}))
+
+	return actual.(*Schema)
+}
+
+// DefaultSchema returns the schema of the model type M for the configuration, as constructors build it
+// when they are not given one.
+//
+//	When the configuration sets nothing but the tag key, it is the schema returned by SchemaOf.
+//	Otherwise, it is built anew. When building fails, every validation fails with an internal error, see Err.
+func DefaultSchema[M any](conf SchemaConfig) *Schema {
+
+	if conf.Operators == nil && conf.Aliases == nil && conf.DefaultSort == nil && conf.MaxLimit <= 0 &&
+		conf.Syntax == nil && conf.Times == nil && conf.Now == nil {
+		return SchemaOf[M](conf.TagKey)
+	}
+
+	return buildSchema[M](conf)
+}
+
+// buildSchema is like NewSchema, but returns a schema holding the error when building fails.
+func buildSchema[M any](conf SchemaConfig) *Schema {
+
+	s, err := NewSchema[M](conf)
+	if err != nil {
+		tagKey := conf.TagKey
+		if tagKey == "" {
+			tagKey = "json"
+		}
+		return &Schema{tagKey: tagKey, err: err}
+	}
+
+	return s
+}
+
+// Err returns why the schema could not be built, if it was returned by SchemaOf or DefaultSchema.
+func (s *Schema) Err() error {
+	return s.err
+}
+
+// TagKey returns the struct tag key naming the fields.
+func (s *Schema) TagKey() string {
+	return s.tagKey
+}
+
+// Keys returns the keys of the queryable fields, in order.
+func (s *Schema) Keys() []string {
+
+	keys := make([]string, 0, len(s.fields))
+	for k := range s.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Field returns the type of the field with the key, or alias.
+func (s *Schema) Field(key string) (r.FieldType, bool) {
+
+	ft, found := s.fields[s.resolve(key)]
+
+	return ft, found
+}
+
+// Operators returns the operators permitted on the field with the key, or alias, in order.
+func (s *Schema) Operators(key string) []string {
+
+	ft, found := s.Field(key)
+	if !found {
+		return nil
+	}
+
+	permitted := PermittedOperators(ft)
+	var ops []string
+	for op, v := range s.operators {
+		if v && permitted[op] {
+			ops = append(ops, op)
+		}
+	}
+	sort.Strings(ops)
+
+	return ops
+}
+
+// Aliases returns a copy of the aliases, mapped to the keys they stand for.
+func (s *Schema) Aliases() map[string]string {
+
+	aliases := make(map[string]string, len(s.aliases))
+	for alias, key := range s.aliases {
+		aliases[alias] = key
+	}
+
+	return aliases
+}
+
+// DefaultSort returns the sort order of searches without sort keys of their own.
+func (s *Schema) DefaultSort() []Sort {
+	return append([]Sort{}, s.defaultSort...)
+}
+
+// MaxLimit returns the maximum number of items in a page of search results.
+func (s *Schema) MaxLimit() int {
+	return s.maxLimit
+}
+
+// Validate validates the queries like ValidateAll, after replacing aliases by the keys they stand for.
+//
+//	The input is left unaltered. Errors report the keys of the input, aliases included.
+func (s *Schema) Validate(queries []Query) ([]Query, error) {
+
+	if s.err != nil {
+		return nil, e.Wrap(s.err, e.ErrInternal)
+	}
+
+	validated, err := validateAll(s.canonical(queries), s.scope())
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		for i := range ve.Errors {
+			ve.Errors[i].Key = queries[ve.Errors[i].Index].Key
+		}
+	}
+
+	return validated, err
+}
+
+// ValidateOptions validates the search options like SearchOptions.ValidateFields, after replacing aliases,
+// and applies the default sort when there are no sort keys.
+//
+//	The input, including its filter, is left unaltered. The limit is not capped, see MaxLimit.
+func (s *Schema) ValidateOptions(opts SearchOptions) (SearchOptions, error) {
+
+	if s.err != nil {
+		return SearchOptions{}, e.Wrap(s.err, e.ErrInternal)
+	}
+
+	sorts := make([]Sort, len(opts.Sort))
+	for i, sort := range opts.Sort {
+		sorts[i] = Sort{Key: s.resolve(sort.Key), Descending: sort.Descending}
+	}
+	if len(sorts) == 0 {
+		sorts = s.DefaultSort()
+	}
+	opts.Sort = sorts
+
+	if opts.Filter != nil {
+		filter := s.canonicalExpression(opts.Filter.Clone())
+		opts.Filter = &filter
+	}
+
+	err := opts.validate(s.scope())
+	if err != nil {
+		return SearchOptions{}, e.Wrap(err, e.ErrBadRequest)
+	}
+
+	return opts, nil
+}
+
+// ParseURL reads the queries and search options from the URL parameters, and validates both.
+//
+//	The limit defaults to, and is capped at, MaxLimit.
+func (s *Schema) ParseURL(input url.Values) ([]Query, SearchOptions, error) {
+
+	if s.err != nil {
+		return nil, SearchOptions{}, e.Wrap(s.err, e.ErrInternal)
+	}
+
+	queries, err := s.syntax.Decode(input)
+	if err != nil {
+		return nil, SearchOptions{}, err
+	}
+	queries, err = s.Validate(queries)
+	if err != nil {
+		return nil, SearchOptions{}, err
+	}
+
+	var o SearchOptions
+	opts, err := o.FromURL(input)
+	if err != nil {
+		return nil, SearchOptions{}, err
+	}
+	opts, err = s.ValidateOptions(opts)
+	if err != nil {
+		return nil, SearchOptions{}, err
+	}
+	if opts.Limit == 0 || opts.Limit > s.maxLimit {
+		opts.Limit = s.maxLimit
+	}
+
+	return queries, opts, nil
+}
+
+// reservedKeys fails on keys and aliases that ColonSyntax would read as search options, so that they could never be queried.
+//
+//	A key is queryable through an alias of another name.
+func (s *Schema) reservedKeys() error {
+
+	aliased := map[string]bool{}
+	for alias, key := range s.aliases {
+		if c.SearchOptionKeys[alias] {
+			return fmt.Errorf("(invalid alias '%s': reserved for search options in colon syntax)", alias)
+		}
+		aliased[key] = true
+	}
+	for _, key := range s.Keys() {
+		if c.SearchOptionKeys[key] && !aliased[key] {
+			return fmt.Errorf("(invalid key '%s': reserved for search options in colon syntax - add an alias for it)", key)
+		}
+	}
+
+	return nil
+}
+
+func (s *Schema) scope() scope {
+	return scope{fields: s.fields, otb: s.operators, now: s.now, times: s.times}
+}
+
+// resolve returns the key an alias stands for, or the key itself.
+func (s *Schema) resolve(key string) string {
+
+	if k, found := s.aliases[key]; found {
+		return k
+	}
+
+	return key
+}
+
+func (s *Schema) canonical(queries []Query) []Query {
+
+	canonical := make([]Query, len(queries))
+	for i, q := range queries {
+		q.Key = s.resolve(q.Key)
+		canonical[i] = q
+	}
+
+	return canonical
+}
+
+// canonicalExpression replaces aliases in the tree, which must not be shared.
+func (s *Schema) canonicalExpression(x Expression) Expression {
+
+	x.Query.Key = s.resolve(x.Query.Key)
+	for i := range x.Children {
+		x.Children[i] = s.canonicalExpression(x.Children[i])
+	}
+
+	return x
+}
@@ -0,0 +1,244 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+)
+
+type schemaUser struct {
+	Name    string    `json:"name"`
+	Age     int       `json:"age"`
+	Active  bool      `json:"active"`
+	Created time.Time `json:"created"`
+}
+
+func ExampleSchemaOf() {
+
+	s := SchemaOf[schemaUser]("json")
+
+	fmt.Println(s.Keys())
+	fmt.Println(s.Operators("active"))
+
+	// Output:
+	// [active age created name]
+	// [EQ IN ISNULL NE NIN NOTNULL]
+}
+
+func ExampleSchema_ParseURL() {
+
+	s, err := NewSchema[schemaUser](SchemaConfig{
+		Aliases:     map[string]string{"years": "age"},
+		DefaultSort: []Sort{{Key: "created", Descending: true}},
+		MaxLimit:    20,
+	})
+	if err != nil {
+		// Handle error...
+	}
+
+	input, _ := url.ParseQuery("q=years,GE,30&limit=500")
+	queries, opts, err := s.ParseURL(input)
+	if err != nil {
+		// Handle error...
+	}
+
+	fmt.Printf("%s %s %T\n", queries[0].Key, queries[0].Operator, queries[0].Value)
+	fmt.Println(opts.Sort, opts.Limit)
+
+	// Output:
+	// age GE int
+	// [{created true}] 20
+}
+
+func Test_SchemaOf_Cached(t *testing.T) {
+
+	if SchemaOf[schemaUser]("json") != SchemaOf[schemaUser]("json") {
+		fmt.Println("SchemaOf should build the schema of a type only once.")
+		t.Fail()
+	}
+	if SchemaOf[schemaUser]("json") == SchemaOf[schemaUser]("db") {
+		fmt.Println("SchemaOf should cache schemas per tag key.")
+		t.Fail()
+	}
+}
+
+func Test_NewSchema_Errors(t *testing.T) {
+
+	configs := []SchemaConfig{
+		{Aliases: map[string]string{"years": "nope"}},
+		{Aliases: map[string]string{"name": "age"}},
+		{DefaultSort: []Sort{{Key: "nope"}}},
+	}
+
+	for _, conf := range configs {
+		if _, err := NewSchema[schemaUser](conf); err == nil {
+			fmt.Printf("%+v: expected an error\n", conf)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Schema_Invalid_Tags(t *testing.T) {
+
+	type ticket struct {
+		Age int `json:"age" query:"age,ops=EQ|GTE"`
+	}
+
+	if _, err := NewSchema[ticket](SchemaConfig{}); err == nil {
+		fmt.Println("NewSchema should fail on unknown operators in query tags.")
+		t.Fail()
+	}
+
+	s := SchemaOf[ticket]("json")
+	_, err := s.Validate([]Query{{Key: "age", Operator: "EQ", Value: "1"}})
+	if s.Err() == nil || !errors.Is(err, e.ErrInternal) {
+		fmt.Println("A schema with invalid tags should fail every validation, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_Schema_Validate(t *testing.T) {
+
+	now := time.Date(2022, 6, 15, 13, 30, 0, 0, time.UTC)
+	s, err := NewSchema[schemaUser](SchemaConfig{
+		Aliases: map[string]string{"since": "created"},
+		Now:     func() time.Time { return now },
+	})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	queries := []Query{{Key: "since", Operator: "GE", Value: "today"}}
+	validated, err := s.Validate(queries)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if validated[0].Key != "created" || validated[0].Value != time.Date(2022, 6, 15, 0, 0, 0, 0, time.UTC) {
+		fmt.Println("Schema.Validate failed to resolve the alias or the schema's clock, got:", validated[0])
+		t.Fail()
+	}
+	if queries[0].Key != "since" || queries[0].Value != "today" {
+		fmt.Println("Schema.Validate should leave its input unaltered, got:", queries[0])
+		t.Fail()
+	}
+
+	filter := Leaf(Query{Key: "since", Operator: "GE", Value: "now"})
+	opts, err := s.ValidateOptions(SearchOptions{Sort: []Sort{{Key: "since"}}, Filter: &filter})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if opts.Sort[0].Key != "created" || opts.Filter.Query.Key != "created" || filter.Query.Key != "since" {
+		fmt.Println("Schema.ValidateOptions failed to resolve aliases in a copy, got:", opts)
+		t.Fail()
+	}
+
+	_, err = s.Validate([]Query{{Key: "active", Operator: "GT", Value: "true"}})
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("Schema.Validate should apply per-field operator restrictions, got:", err)
+		t.Fail()
+	}
+
+	_, err = s.Validate([]Query{{Key: "name", Operator: "EQ", Value: "a"}, {Key: "since", Operator: "GE", Value: "soon"}})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || len(ve.Errors) != 1 || ve.Errors[0].Index != 1 || ve.Errors[0].Key != "since" {
+		fmt.Println("Schema.Validate should report the keys it was given, aliases included, got:", err)
+		t.Fail()
+	}
+}
+
+func Test_Schema_Copies_Time_Settings(t *testing.T) {
+
+	type Event struct {
+		Created time.Time `json:"created"`
+	}
+
+	now := time.Date(2022, 6, 15, 13, 30, 0, 0, time.UTC)
+	defer func(clock func() time.Time, tc TimeConfig) { Now, Times = clock, tc }(Now, Times)
+	Now = func() time.Time { return now }
+
+	s, err := NewSchema[Event](SchemaConfig{})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+
+	Now = func() time.Time { return now.Add(time.Hour) }
+	Times = TimeConfig{Layouts: []string{LayoutUnix}}
+
+	validated, err := s.Validate([]Query{
+		{Key: "created", Operator: "LT", Value: "now"},
+		{Key: "created", Operator: "GT", Value: "2022-06-15_10:00"},
+	})
+	if err != nil {
+		fmt.Println("A schema should keep the time layouts it was built with, got:", err)
+		t.FailNow()
+	}
+	if validated[0].Value != now {
+		fmt.Println("A schema should keep the clock it was built with, got:", validated[0].Value)
+		t.Fail()
+	}
+}
+
+func Test_NewSchema_Colon_Syntax_Reserved_Keys(t *testing.T) {
+
+	type listing struct {
+		Name  string `json:"name"`
+		Limit int    `json:"limit"`
+	}
+
+	if _, err := NewSchema[listing](SchemaConfig{Syntax: ColonSyntax{}}); err == nil {
+		fmt.Println("NewSchema should fail on keys colon syntax reads as search options.")
+		t.Fail()
+	}
+	if _, err := NewSchema[listing](SchemaConfig{Syntax: ColonSyntax{}, Aliases: map[string]string{"sort": "name"}}); err == nil {
+		fmt.Println("NewSchema should fail on aliases colon syntax reads as search options.")
+		t.Fail()
+	}
+	if _, err := NewSchema[listing](SchemaConfig{}); err != nil {
+		fmt.Println("Other syntaxes should accept any key, got:", err)
+		t.Fail()
+	}
+
+	s, err := NewSchema[listing](SchemaConfig{Syntax: ColonSyntax{}, Aliases: map[string]string{"max": "limit"}})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	queries, opts, err := s.ParseURL(url.Values{"max": {"le:10"}, "limit": {"5"}})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if len(queries) != 1 || queries[0].Key != "limit" || queries[0].Value != 10 || opts.Limit != 5 {
+		fmt.Println("An alias should make a reserved key queryable, got:", queries, opts)
+		t.Fail()
+	}
+}
+
+func Test_DefaultSchema(t *testing.T) {
+
+	if s := DefaultSchema[schemaUser](SchemaConfig{}); s != SchemaOf[schemaUser]("json") || s.TagKey() != "json" {
+		fmt.Println("DefaultSchema should return the cached schema when only the tag key is set.")
+		t.Fail()
+	}
+
+	s := DefaultSchema[schemaUser](SchemaConfig{TagKey: "db", MaxLimit: 10})
+	if s == SchemaOf[schemaUser]("db") || s.MaxLimit() != 10 || s.TagKey() != "db" || s.Err() != nil {
+		fmt.Println("DefaultSchema should build a schema for any other configuration, got:", s.MaxLimit(), s.TagKey(), s.Err())
+		t.Fail()
+	}
+
+	s = DefaultSchema[schemaUser](SchemaConfig{Aliases: map[string]string{"years": "nope"}})
+	_, err := s.Validate([]Query{{Key: "age", Operator: "EQ", Value: "1"}})
+	if s.Err() == nil || !errors.Is(err, e.ErrInternal) {
+		fmt.Println("A schema that could not be built should fail every validation, got:", err)
+		t.Fail()
+	}
+}
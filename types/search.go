@@ -0,0 +1,237 @@
+package types
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	c "github.com/pergamenum/go-consensus-standards/constants"
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	r "github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+// Sort orders search results by a single key.
+type Sort struct {
+	Key        string
+	Descending bool
+}
+
+// SearchOptions limit and order the results of a search.
+//
+//	A Limit of zero means no limit.
+//	Cursor, when set, takes precedence over Offset. It is the encoded offset of the next page, as returned in Page.NextCursor,
+//	so pages may skip or repeat items when matches are created or deleted in between.
+//	Filter, when set, must hold in addition to the queries of the search.
+type SearchOptions struct {
+	Limit  int
+	Offset int
+	Cursor string
+	Sort   []Sort
+	Filter *Expression
+}
+
+// Page is a single page of search results.
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Total is the number of matches across all pages.
+	Total      int    `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// FromURL parses 'sort=-created,name&limit=50&offset=100&cursor=...&filter=...' into SearchOptions.
+//
+//	Parameters other than those in constants.SearchOptionKeys are ignored.
+func (o *SearchOptions) FromURL(input url.Values) (SearchOptions, error) {
+
+	var opts SearchOptions
+
+	bad := func(key string) (SearchOptions, error) {
+		cause := fmt.Sprintf("(invalid '%s': '%s')", key, input.Get(key))
+		return SearchOptions{}, e.Wrap(cause, e.ErrBadRequest)
+	}
+
+	for key, values := range input {
+		if c.SearchOptionKeys[key] && len(values) > 1 {
+			cause := fmt.Sprintf("(parameter '%s' must not be repeated)", key)
+			return SearchOptions{}, e.Wrap(cause, e.ErrBadRequest)
+		}
+	}
+
+	if s := input.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 0 {
+			return bad("limit")
+		}
+		opts.Limit = limit
+	}
+
+	if s := input.Get("offset"); s != "" {
+		offset, err := strconv.Atoi(s)
+		if err != nil || offset < 0 {
+			return bad("offset")
+		}
+		opts.Offset = offset
+	}
+
+	opts.Cursor = input.Get("cursor")
+	if _, err := opts.Start(); err != nil {
+		return SearchOptions{}, err
+	}
+
+	var x Expression
+	filter, err := x.FromURL(input)
+	if err != nil {
+		return SearchOptions{}, err
+	}
+	opts.Filter = filter
+
+	if s := input.Get("sort"); s != "" {
+		for _, key := range strings.Split(s, ",") {
+			key = strings.TrimSpace(key)
+			descending := strings.HasPrefix(key, "-")
+			key = strings.TrimPrefix(key, "-")
+			if key == "" {
+				return bad("sort")
+			}
+			opts.Sort = append(opts.Sort, Sort{Key: key, Descending: descending})
+		}
+	}
+
+	return opts, nil
+}
+
+// Validate checks the sort keys against the tag-to-type map used for queries, and validates the filter.
+func (o *SearchOptions) Validate(ttt map[string]string, otb map[string]bool) error {
+	return o.ValidateFields(FieldTypes(ttt), otb)
+}
+
+// ValidateFields is like Validate, but takes the tag-to-field-type map used by Query.ValidateFields.
+func (o *SearchOptions) ValidateFields(fields map[string]r.FieldType, otb map[string]bool) error {
+	return o.validate(newScope(fields, otb))
+}
+
+func (o *SearchOptions) validate(s scope) error {
+
+	if o == nil {
+		return fmt.Errorf("(search options were nil)")
+	}
+
+	var sb strings.Builder
+	if o.Limit < 0 {
+		sb.WriteString(fmt.Sprintf("(invalid limit '%d')", o.Limit))
+	}
+	if o.Offset < 0 {
+		sb.WriteString(fmt.Sprintf("(invalid offset '%d')", o.Offset))
+	}
+	if _, err := o.Start(); err != nil {
+		sb.WriteString("(invalid cursor)")
+	}
+	for _, sort := range o.Sort {
+		if _, found := s.fields[sort.Key]; !found {
+			var vks []string
+			for vk := range s.fields {
+				vks = append(vks, vk)
+			}
+			sb.WriteString(fmt.Sprintf("(invalid sort key '%v' ", sort.Key))
+			sb.WriteString(fmt.Sprintf("- valid keys: '%v')", strings.Join(vks, " ")))
+		}
+	}
+
+	if o.Filter != nil {
+		if err := o.Filter.validate(s); err != nil {
+			sb.WriteString(err.Error())
+		}
+	}
+
+	if sb.Len() > 0 {
+		return fmt.Errorf("(invalid search options: %v)", sb.String())
+	}
+
+	return nil
+}
+
+// Start returns the index of the first item of the page, decoded from the cursor when one is set.
+//
+//	Cursors are opaque to clients, but are no more than an offset: they are neither signed nor tied to a sort order.
+func (o SearchOptions) Start() (int, error) {
+
+	if o.Cursor == "" {
+		return o.Offset, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(o.Cursor)
+	if err != nil {
+		return 0, e.Wrap("(invalid cursor)", e.ErrBadRequest)
+	}
+
+	start, err := strconv.Atoi(strings.TrimPrefix(string(b), "offset:"))
+	if err != nil || start < 0 || !strings.HasPrefix(string(b), "offset:") {
+		return 0, e.Wrap("(invalid cursor)", e.ErrBadRequest)
+	}
+
+	return start, nil
+}
+
+// Next returns the cursor of the page after the one starting at 'start' and holding 'count' of 'total' items.
+//
+//	Returns an empty string when there are no more pages.
+func (o SearchOptions) Next(start, count, total int) string {
+
+	next := start + count
+	if count == 0 || next >= total {
+		return ""
+	}
+
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(next)))
+}
+
+// PageOf returns the page of the items selected by the cursor or offset and the limit of the options.
+//
+//	The items must be every match of the search, already filtered and sorted.
+func PageOf[T any](items []T, opts SearchOptions) (page Page[T], err error) {
+
+	start, err := opts.Start()
+	if err != nil {
+		return page, err
+	}
+
+	total := len(items)
+	end := total
+	if start > total {
+		start = total
+	}
+	if opts.Limit > 0 && opts.Limit < total-start {
+		end = start + opts.Limit
+	}
+
+	page.Items = items[start:end]
+	page.Total = total
+	page.NextCursor = opts.Next(start, end-start, total)
+
+	return page, nil
+}
+
+// SearchPageOf serves a page from a plain search, for implementations that are not an interfaces.Pager.
+//
+//	Sorting and filtering are rejected, as a plain search can apply neither.
+func SearchPageOf[T any](
+	ctx context.Context, search func(context.Context, []Query) ([]T, error), query []Query, opts SearchOptions,
+) (Page[T], error) {
+
+	if len(opts.Sort) > 0 || opts.Filter != nil {
+		return Page[T]{}, e.Wrap("(sorting and filtering are not supported by this search)", e.ErrBadRequest)
+	}
+	if _, err := opts.Start(); err != nil {
+		return Page[T]{}, err
+	}
+
+	items, err := search(ctx, query)
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	return PageOf(items, opts)
+}
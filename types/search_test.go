@@ -0,0 +1,57 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"testing"
+)
+
+func ExampleSearchOptions_FromURL() {
+
+	input, _ := url.ParseQuery("q=age,GE,30&sort=-created,name&limit=50")
+
+	var o SearchOptions
+	opts, err := o.FromURL(input)
+	if err != nil {
+		// Handle error...
+	}
+	fmt.Println("1:", opts.Limit, opts.Sort)
+
+	next := opts.Next(0, 50, 120)
+	opts.Cursor = next
+	start, _ := opts.Start()
+	fmt.Println("2:", start)
+
+	fmt.Println("3:", opts.Next(100, 20, 120) == "")
+
+	// Output:
+	// 1: 50 [{created true} {name false}]
+	// 2: 50
+	// 3: true
+}
+
+func Test_PageOf(t *testing.T) {
+
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		opts     SearchOptions
+		expected []int
+		next     bool
+	}{
+		{SearchOptions{Limit: 2}, []int{1, 2}, true},
+		{SearchOptions{Offset: 3, Limit: 2}, []int{4, 5}, false},
+		{SearchOptions{Offset: 1, Limit: math.MaxInt}, []int{2, 3, 4, 5}, false},
+		{SearchOptions{Offset: 9, Limit: 2}, []int{}, false},
+		{SearchOptions{Offset: 4}, []int{5}, false},
+	}
+
+	for _, test := range tests {
+		page, err := PageOf(items, test.opts)
+		if err != nil || fmt.Sprint(page.Items) != fmt.Sprint(test.expected) || (page.NextCursor != "") != test.next || page.Total != 5 {
+			fmt.Printf("%+v: got %+v, %v\n", test.opts, page, err)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	c "github.com/pergamenum/go-consensus-standards/constants"
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+)
+
+// URLSyntax reads queries from URL parameters, and writes them back.
+//
+// The parameters of SearchOptions, see constants.SearchOptionKeys, may coexist with queries in any syntax.
+// Within values, a backslash escapes the next character, and double quotes enclose characters taken literally.
+type URLSyntax interface {
+	Decode(input url.Values) ([]Query, error)
+	Encode(queries []Query) url.Values
+}
+
+// CommaSyntax is the 'q=<key>,<operator>,<value>' form of Query.FromURL, e.g. 'q=name,EQ,"Smith, John"'.
+type CommaSyntax struct{}
+
+// BracketSyntax is the 'filter[<key>][<operator>]=<value>' form, e.g. 'filter[age][gt]=30'.
+type BracketSyntax struct{}
+
+// ColonSyntax is the '<key>=<operator>:<value>' form, e.g. 'age=gt:30'.
+//
+//	Every parameter other than those of SearchOptions is a query. Without a valid operator, the operator is EQ.
+//	Fields named like those parameters, such as 'sort', are queried through an alias: NewSchema fails without one.
+type ColonSyntax struct{}
+
+func (CommaSyntax) Decode(input url.Values) ([]Query, error) {
+
+	cause := "(query must be q=<key>,<operator>,<value> - list values are separated by '|')"
+	err := e.Wrap(cause, e.ErrBadRequest)
+
+	// Ensure that any non-conforming query is reported back as invalid.
+	for key := range input {
+		if key != "q" && !c.SearchOptionKeys[key] {
+			return nil, err
+		}
+	}
+
+	qss, found := input["q"]
+	if !found {
+		return []Query{}, nil
+	}
+
+	var queries []Query
+	for _, qs := range qss {
+
+		query, ok := parseQuery(qs)
+		if !ok {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+
+	return queries, nil
+}
+
+func (CommaSyntax) Encode(queries []Query) url.Values {
+
+	values := url.Values{}
+	for _, q := range queries {
+		values.Add("q", formatQuery(q))
+	}
+
+	return values
+}
+
+var bracketParam = regexp.MustCompile(`^filter\[([^\[\]]+)\]\[([A-Za-z]+)\]$`)
+
+func (BracketSyntax) Decode(input url.Values) ([]Query, error) {
+
+	queries := []Query{}
+	for _, key := range sortedKeys(input) {
+
+		if c.SearchOptionKeys[key] {
+			continue
+		}
+		m := bracketParam.FindStringSubmatch(key)
+		if m == nil {
+			cause := fmt.Sprintf("(parameter '%s' must be filter[<key>][<operator>]=<value>)", key)
+			return nil, e.Wrap(cause, e.ErrBadRequest)
+		}
+
+		for _, v := range input[key] {
+			q, err := newQuery(m[1], strings.ToUpper(m[2]), v)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, q)
+		}
+	}
+
+	return queries, nil
+}
+
+func (BracketSyntax) Encode(queries []Query) url.Values {
+
+	values := url.Values{}
+	for _, q := range queries {
+		key := fmt.Sprintf("filter[%s][%s]", q.Key, strings.ToLower(q.Operator))
+		values.Add(key, formatQueryValue(q.Value))
+	}
+
+	return values
+}
+
+func (ColonSyntax) Decode(input url.Values) ([]Query, error) {
+
+	queries := []Query{}
+	for _, key := range sortedKeys(input) {
+
+		if c.SearchOptionKeys[key] {
+			continue
+		}
+
+		for _, v := range input[key] {
+			op, value := "EQ", v
+			if i := strings.Index(v, ":"); i > 0 && c.ValidOperators[strings.ToUpper(v[:i])] {
+				op, value = strings.ToUpper(v[:i]), v[i+1:]
+			}
+			q, err := newQuery(key, op, value)
+			if err != nil {
+				return nil, err
+			}
+			queries = append(queries, q)
+		}
+	}
+
+	return queries, nil
+}
+
+func (ColonSyntax) Encode(queries []Query) url.Values {
+
+	values := url.Values{}
+	for _, q := range queries {
+		values.Add(q.Key, strings.ToLower(q.Operator)+":"+formatQueryValue(q.Value))
+	}
+
+	return values
+}
+
+// sortedKeys returns the parameter names in order, so that queries are decoded in a stable order.
+func sortedKeys(input url.Values) []string {
+
+	keys := make([]string, 0, len(input))
+	for k := range input {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// newQuery builds a query from the escaped string form of its value, splitting list values.
+func newQuery(key, op, raw string) (Query, error) {
+
+	q := Query{Key: key, Operator: op}
+
+	var err error
+	if c.ValidSetOperators[op] || c.ValidRangeOperators[op] {
+		q.Value, err = parseList(raw)
+	} else {
+		q.Value, err = unescape(raw)
+	}
+	if err != nil {
+		cause := fmt.Sprintf("(invalid value for key '%s')", key)
+		return Query{}, e.Wrap(cause, e.Wrap(err, e.ErrBadRequest))
+	}
+
+	return q, nil
+}
@@ -0,0 +1,124 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func ExampleBracketSyntax() {
+
+	input, _ := url.ParseQuery("filter[age][gt]=30&filter[status][in]=open|new&limit=10&sort=-age")
+
+	queries, err := BracketSyntax{}.Decode(input)
+	if err != nil {
+		// Handle error...
+	}
+	for _, q := range queries {
+		fmt.Println(q.Key, q.Operator, q.Value)
+	}
+
+	// Output:
+	// age GT 30
+	// status IN [open new]
+}
+
+func Test_URLSyntax_Round_Trip(t *testing.T) {
+
+	queries := []Query{
+		{Key: "age", Operator: "GT", Value: "30"},
+		{Key: "name", Operator: "EQ", Value: "Smith, John (Jr.)"},
+		{Key: "tag", Operator: "IN", Value: []any{"a|b", `c"d`}},
+	}
+
+	syntaxes := []URLSyntax{CommaSyntax{}, BracketSyntax{}, ColonSyntax{}}
+	for _, syntax := range syntaxes {
+
+		encoded := syntax.Encode(queries)
+		encoded.Set("limit", "10")
+		encoded.Set("sort", "-age")
+
+		// Through a real URL, to include the encoding of the parameters themselves.
+		input, err := url.ParseQuery(encoded.Encode())
+		if err != nil {
+			fmt.Println(err)
+			t.FailNow()
+		}
+
+		decoded, err := syntax.Decode(input)
+		if err != nil {
+			fmt.Printf("%T: %v\n", syntax, err)
+			t.Fail()
+			continue
+		}
+
+		got := map[string]Query{}
+		for _, q := range decoded {
+			got[q.Key] = q
+		}
+		for _, q := range queries {
+			if !reflect.DeepEqual(got[q.Key], q) {
+				fmt.Printf("%T: got %#v, want %#v\n", syntax, got[q.Key], q)
+				t.Fail()
+			}
+		}
+	}
+}
+
+func Test_URLSyntax_Decode(t *testing.T) {
+
+	tests := []struct {
+		syntax URLSyntax
+		input  string
+		want   []Query
+	}{
+		{CommaSyntax{}, `q=name,EQ,"Smith, John"`, []Query{{Key: "name", Operator: "EQ", Value: "Smith, John"}}},
+		{CommaSyntax{}, `q=name,EQ,Smith\,+John`, []Query{{Key: "name", Operator: "EQ", Value: "Smith, John"}}},
+		{ColonSyntax{}, `age=gte:30`, []Query{{Key: "age", Operator: "EQ", Value: "gte:30"}}},
+		{ColonSyntax{}, `created=le:2022-01-01_13:30`, []Query{{Key: "created", Operator: "LE", Value: "2022-01-01_13:30"}}},
+		{ColonSyntax{}, `name=Jeff&offset=5`, []Query{{Key: "name", Operator: "EQ", Value: "Jeff"}}},
+	}
+
+	for _, test := range tests {
+		input, _ := url.ParseQuery(test.input)
+		got, err := test.syntax.Decode(input)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			fmt.Printf("%s: got %v (%v), want %v\n", test.input, got, err, test.want)
+			t.Fail()
+		}
+	}
+
+	bad := []struct {
+		syntax URLSyntax
+		input  string
+	}{
+		{CommaSyntax{}, `q=name,EQ,Smith,John`},
+		{CommaSyntax{}, `q=name,EQ,"Smith`},
+		{CommaSyntax{}, `name=Jeff`},
+		{BracketSyntax{}, `filter[age]=30`},
+		{BracketSyntax{}, `age=30`},
+	}
+
+	for _, test := range bad {
+		input, _ := url.ParseQuery(test.input)
+		if _, err := test.syntax.Decode(input); err == nil {
+			fmt.Printf("%T %s: expected an error\n", test.syntax, test.input)
+			t.Fail()
+		}
+	}
+}
+
+func Test_Expression_Escaped_Values(t *testing.T) {
+
+	x := Or(
+		Leaf(Query{Key: "name", Operator: "EQ", Value: "Smith, John (Jr.)"}),
+		Leaf(Query{Key: "name", Operator: "EQ", Value: `a\b`}),
+	)
+
+	parsed, err := ParseExpression(x.String())
+	if err != nil || !reflect.DeepEqual(parsed, x) {
+		fmt.Printf("%s: got %v (%v)\n", x, parsed, err)
+		t.Fail()
+	}
+}
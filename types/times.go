@@ -0,0 +1,135 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	c "github.com/pergamenum/go-consensus-standards/constants"
+)
+
+// Layouts for TimeConfig, besides those of the time package such as time.RFC3339.
+const (
+	// LayoutDateOnly is a date without a time of day, at midnight.
+	LayoutDateOnly = "2006-01-02"
+	// LayoutUnix is a number of seconds since the Unix epoch.
+	LayoutUnix = "unix"
+	// LayoutUnixMilli is a number of milliseconds since the Unix epoch.
+	LayoutUnixMilli = "unixmilli"
+)
+
+// TimeConfig controls how query values are parsed as, and formatted from, times.
+type TimeConfig struct {
+	// Layouts are tried in order when parsing. The first one is used when formatting.
+	// Defaults to constants.QueryTimeFormat.
+	Layouts []string
+	// Location is assumed for layouts without a timezone, and for relative times such as 'today'. Defaults to UTC.
+	Location *time.Location
+}
+
+// Times is the TimeConfig used when validating queries without a Schema, and when writing queries in URL form.
+//
+//	Set it once, before serving requests: it is read without synchronization. Schemas copy it when they are built,
+//	and SchemaConfig.Times configures a single schema.
+var Times = TimeConfig{Layouts: []string{c.QueryTimeFormat}}
+
+func (tc TimeConfig) layouts() []string {
+
+	if len(tc.Layouts) == 0 {
+		return []string{c.QueryTimeFormat}
+	}
+
+	return tc.Layouts
+}
+
+func (tc TimeConfig) location() *time.Location {
+
+	if tc.Location == nil {
+		return time.UTC
+	}
+
+	return tc.Location
+}
+
+// Parse parses the string with the first layout that accepts it.
+func (tc TimeConfig) Parse(s string) (time.Time, error) {
+
+	loc := tc.location()
+	for _, layout := range tc.layouts() {
+
+		switch layout {
+		case LayoutUnix, LayoutUnixMilli:
+			n, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				continue
+			}
+			if layout == LayoutUnix {
+				return time.Unix(n, 0).In(loc), nil
+			}
+			return time.UnixMilli(n).In(loc), nil
+
+		default:
+			t, err := time.ParseInLocation(layout, s, loc)
+			if err == nil {
+				return t, nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("(value '%s' matches no time layout)", s)
+}
+
+// Format formats the time with the first layout.
+func (tc TimeConfig) Format(t time.Time) string {
+
+	switch layout := tc.layouts()[0]; layout {
+	case LayoutUnix:
+		return strconv.FormatInt(t.Unix(), 10)
+	case LayoutUnixMilli:
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default:
+		return t.In(tc.location()).Format(layout)
+	}
+}
+
+// Hint returns the human-readable form of every layout, such as 'YYYY-MM-DD_hh:mm'. Intended for use with error reporting.
+func (tc TimeConfig) Hint() string {
+
+	hints := make([]string, len(tc.layouts()))
+	for i, layout := range tc.layouts() {
+		hints[i] = "'" + layoutHint(layout) + "'"
+	}
+
+	return strings.Join(hints, ", ")
+}
+
+// hintReplacer rewrites the reference time of a layout into placeholders.
+var hintReplacer = strings.NewReplacer(
+	"2006", "YYYY",
+	"01", "MM",
+	"02", "DD",
+	"15", "hh",
+	"04", "mm",
+	"05", "ss",
+	".000000000", ".fffffffff",
+	".999999999", ".fffffffff",
+	".000", ".fff",
+	".999", ".fff",
+	"Z07:00", "Z|±hh:mm",
+	"-07:00", "±hh:mm",
+	"-0700", "±hhmm",
+	"MST", "TZ",
+)
+
+func layoutHint(layout string) string {
+
+	switch layout {
+	case LayoutUnix:
+		return "unix seconds"
+	case LayoutUnixMilli:
+		return "unix milliseconds"
+	default:
+		return hintReplacer.Replace(layout)
+	}
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pergamenum/go-consensus-standards/constants"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+func ExampleTimeConfig_Hint() {
+
+	tc := TimeConfig{Layouts: []string{constants.QueryTimeFormat, time.RFC3339Nano, LayoutDateOnly, LayoutUnixMilli}}
+	fmt.Println(tc.Hint())
+
+	// Output:
+	// 'YYYY-MM-DD_hh:mm', 'YYYY-MM-DDThh:mm:ss.fffffffffZ|±hh:mm', 'YYYY-MM-DD', 'unix milliseconds'
+}
+
+func Test_TimeConfig(t *testing.T) {
+
+	oslo := time.FixedZone("CET", 3600)
+	defer func(tc TimeConfig) { Times = tc }(Times)
+	Times = TimeConfig{
+		Layouts:  []string{constants.QueryTimeFormat, time.RFC3339, LayoutDateOnly, LayoutUnix},
+		Location: oslo,
+	}
+
+	type Event struct {
+		Created time.Time `json:"created"`
+	}
+	fields := reflection.MapTagToFieldType("json", Event{})
+
+	tests := map[string]time.Time{
+		"2022-06-15_13:30":          time.Date(2022, 6, 15, 13, 30, 0, 0, oslo),
+		"2022-06-15T13:30:05Z":      time.Date(2022, 6, 15, 13, 30, 5, 0, time.UTC),
+		"2022-06-15T13:30:05+02:00": time.Date(2022, 6, 15, 11, 30, 5, 0, time.UTC),
+		"2022-06-15":                time.Date(2022, 6, 15, 0, 0, 0, 0, oslo),
+		"1655299805":                time.Date(2022, 6, 15, 13, 30, 5, 0, time.UTC),
+	}
+
+	for value, want := range tests {
+		q := Query{Key: "created", Operator: "GE", Value: value}
+		err := q.ValidateFields(fields, constants.ValidOperators)
+		if err != nil {
+			fmt.Println(value, err)
+			t.Fail()
+			continue
+		}
+		if got := q.Value.(time.Time); !got.Equal(want) {
+			fmt.Printf("%s: got %v, want %v\n", value, got, want)
+			t.Fail()
+		}
+	}
+
+	q := Query{Key: "created", Operator: "GE", Value: "15.06.2022"}
+	if err := q.ValidateFields(fields, constants.ValidOperators); err == nil {
+		fmt.Println("Values matching no layout should be rejected.")
+		t.Fail()
+	}
+
+	formatted := formatValue(time.Date(2022, 6, 15, 12, 30, 0, 0, time.UTC))
+	if formatted != "2022-06-15_13:30" {
+		fmt.Println("Times should be formatted with the first layout in the configured location, got:", formatted)
+		t.Fail()
+	}
+}
@@ -26,17 +26,16 @@ func NewUpdate(input any) (Update, error) {
 		return nil, cause
 	}
 
-	t := v.Type()
 	update := Update{}
-	for i := 0; i < t.NumField(); i++ {
+	for _, f := range r.TaggedFields(v.Type(), "update") {
 
-		key := t.Field(i).Tag.Get("update")
-		if key == "" {
+		key := f.Tag
+		val, ok := r.FieldByIndex(v, f.Index)
+		if !ok {
+			// Promoted through a nil embedded pointer.
 			continue
 		}
 
-		val := v.Field(i)
-
 		for val.Kind() == reflect.Pointer && !val.IsNil() {
 			val = val.Elem()
 		}
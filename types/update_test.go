@@ -0,0 +1,47 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_NewUpdate_Embedded(t *testing.T) {
+
+	type Audit struct {
+		Owner string `update:"owner"`
+	}
+	type Shared struct {
+		Owner string `update:"owner"`
+	}
+	type Document struct {
+		Title string `update:"title"`
+		*Audit
+	}
+	type Ambiguous struct {
+		Audit
+		Shared
+	}
+
+	update, err := NewUpdate(Document{Title: "a", Audit: &Audit{Owner: "Alice"}})
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	if !reflect.DeepEqual(update, Update{"title": "a", "owner": "Alice"}) {
+		fmt.Println("NewUpdate failed to include promoted fields, got:", update)
+		t.Fail()
+	}
+
+	update, _ = NewUpdate(Document{Title: "a"})
+	if _, found := update["owner"]; found {
+		fmt.Println("NewUpdate should skip fields behind a nil embedded pointer.")
+		t.Fail()
+	}
+
+	update, _ = NewUpdate(Ambiguous{Audit: Audit{Owner: "Alice"}, Shared: Shared{Owner: "Bob"}})
+	if len(update) != 0 {
+		fmt.Println("NewUpdate should leave out ambiguous promoted fields, got:", update)
+		t.Fail()
+	}
+}
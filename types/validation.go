@@ -0,0 +1,66 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	r "github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+// QueryError describes what is wrong with a single query of a list.
+type QueryError struct {
+	Index   int    `json:"index"`
+	Key     string `json:"key"`
+	Problem string `json:"problem"`
+}
+
+// ValidationError holds one QueryError per invalid query of a list, in list order.
+//
+//	It unwraps to ehandler.ErrBadRequest.
+type ValidationError struct {
+	Errors []QueryError
+}
+
+func (v *ValidationError) Error() string {
+
+	var sb strings.Builder
+	for _, qe := range v.Errors {
+		sb.WriteString(fmt.Sprintf("(query %d '%s': %s)", qe.Index, qe.Key, qe.Problem))
+	}
+
+	return fmt.Sprintf("(invalid queries: %s)", sb.String())
+}
+
+func (v *ValidationError) Unwrap() error {
+	return e.ErrBadRequest
+}
+
+// ValidateAll validates every query like Query.ValidateFields, without stopping at the first invalid one.
+//
+// The input is left unaltered: the converted queries are returned in a new slice.
+// When any query is invalid, the error is a *ValidationError describing each of them.
+func ValidateAll(queries []Query, fields map[string]r.FieldType, otb map[string]bool) ([]Query, error) {
+	return validateAll(queries, newScope(fields, otb))
+}
+
+func validateAll(queries []Query, s scope) ([]Query, error) {
+
+	validated := make([]Query, len(queries))
+	var errs []QueryError
+	for i, q := range queries {
+
+		// Validation replaces the value rather than altering it, so a shallow copy suffices.
+		problem := q.problem(s)
+		if problem != "" {
+			errs = append(errs, QueryError{Index: i, Key: q.Key, Problem: problem})
+		}
+		validated[i] = q
+	}
+
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	return validated, nil
+}
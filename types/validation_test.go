@@ -0,0 +1,75 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/pergamenum/go-consensus-standards/constants"
+	e "github.com/pergamenum/go-consensus-standards/ehandler"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+func ExampleValidateAll() {
+
+	type User struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	fields := reflection.MapTagToFieldType("json", User{})
+	otb := constants.ValidOperators
+
+	queries := []Query{
+		{Key: "name", Operator: "EQ", Value: "Jeff"},
+		{Key: "age", Operator: "GE", Value: "old"},
+		{Key: "age", Operator: "LT", Value: "40"},
+	}
+
+	_, err := ValidateAll(queries, fields, otb)
+
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		for _, qe := range ve.Errors {
+			fmt.Println(qe.Index, qe.Key)
+		}
+	}
+
+	validated, _ := ValidateAll(queries[2:], fields, otb)
+	fmt.Printf("%T %T\n", queries[2].Value, validated[0].Value)
+
+	// Output:
+	// 1 age
+	// string int
+}
+
+func Test_ValidateAll(t *testing.T) {
+
+	type Ticket struct {
+		Status string `json:"status"`
+	}
+
+	fields := reflection.MapTagToFieldType("json", Ticket{})
+	list := []any{"open", "closed"}
+	queries := []Query{
+		{Key: "status", Operator: "IN", Value: list},
+		{Key: "owner", Operator: "EQ", Value: "x"},
+	}
+
+	validated, err := ValidateAll(queries[:1], fields, constants.ValidOperators)
+	if err != nil {
+		fmt.Println(err)
+		t.FailNow()
+	}
+	validated[0].Value.([]any)[0] = "changed"
+	if list[0] != "open" {
+		fmt.Println("ValidateAll should not share list values with its input.")
+		t.Fail()
+	}
+
+	_, err = ValidateAll(queries, fields, constants.ValidOperators)
+	if !errors.Is(err, e.ErrBadRequest) {
+		fmt.Println("ValidateAll should unwrap to ErrBadRequest, got:", err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,123 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	c "github.com/pergamenum/go-consensus-standards/constants"
+	r "github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+// Now is the clock relative times, such as 'now-24h', are resolved against when validating without a Schema.
+//
+//	Set it once, before serving requests: it is read without synchronization. Schemas copy it when they are built,
+//	and SchemaConfig.Now configures a single schema, for example to fix the time in tests.
+var Now = time.Now
+
+// Ref is a query value referring to another field of the same entity, written '@key' in string form.
+//
+// For example, 'updated GT @created' matches entities updated after they were created.
+type Ref string
+
+func (ref Ref) String() string {
+	return c.QueryRefPrefix + string(ref)
+}
+
+// reference reports whether the value is a string naming one of the fields with QueryRefPrefix.
+//
+//	Any other string, even one with the prefix, is a literal value.
+func (q *Query) reference(fields map[string]r.FieldType) (string, bool) {
+
+	switch v := q.Value.(type) {
+	case Ref:
+		return string(v), true
+	case string:
+		if !strings.HasPrefix(v, c.QueryRefPrefix) {
+			return "", false
+		}
+		key := strings.TrimPrefix(v, c.QueryRefPrefix)
+		_, found := fields[key]
+		return key, found
+	default:
+		return "", false
+	}
+}
+
+// validateRef checks that the referenced field holds values of the same base type as the field of type 'ft'.
+func (q *Query) validateRef(fields map[string]r.FieldType, ft r.FieldType, key string) error {
+
+	rt, found := fields[key]
+	if !found {
+		return fmt.Errorf("(invalid reference '%s%s' - no such key)", c.QueryRefPrefix, key)
+	}
+	if rt.Elem != nil || rt.Base != ft.Base {
+		return fmt.Errorf("(reference '%s%s' of type '%s' is not comparable with '%s')", c.QueryRefPrefix, key, rt, ft)
+	}
+
+	q.Value = Ref(key)
+
+	return nil
+}
+
+// relativeAnchors resolve the named points in time, relative to now.
+var relativeAnchors = map[string]func(now time.Time) time.Time{
+	"now": func(now time.Time) time.Time {
+		return now
+	},
+	"today": func(now time.Time) time.Time {
+		return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	},
+	"startOfWeek": func(now time.Time) time.Time {
+		// Weeks start on Monday.
+		days := (int(now.Weekday()) + 6) % 7
+		return time.Date(now.Year(), now.Month(), now.Day()-days, 0, 0, 0, 0, now.Location())
+	},
+	"startOfMonth": func(now time.Time) time.Time {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	},
+	"startOfYear": func(now time.Time) time.Time {
+		return time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	},
+}
+
+// parseRelativeTime resolves a relative time, such as 'now-24h' or 'today-7d', against now.
+//
+// The offset is either a whole number of calendar days, or anything accepted by time.ParseDuration.
+//
+//	Reports false when the string does not start with a named point in time.
+func parseRelativeTime(s string, now time.Time) (time.Time, bool, error) {
+
+	for name, anchor := range relativeAnchors {
+
+		if !strings.HasPrefix(s, name) {
+			continue
+		}
+		offset := strings.TrimPrefix(s, name)
+		if offset != "" && offset[0] != '+' && offset[0] != '-' {
+			continue
+		}
+
+		t := anchor(now)
+		if offset == "" {
+			return t, true, nil
+		}
+
+		if strings.HasSuffix(offset, "d") {
+			days, err := strconv.Atoi(strings.TrimSuffix(offset, "d"))
+			if err != nil {
+				return time.Time{}, true, fmt.Errorf("(invalid offset '%s')", offset)
+			}
+			return t.AddDate(0, 0, days), true, nil
+		}
+
+		d, err := time.ParseDuration(offset)
+		if err != nil {
+			return time.Time{}, true, fmt.Errorf("(invalid offset '%s')", offset)
+		}
+		return t.Add(d), true, nil
+	}
+
+	return time.Time{}, false, nil
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pergamenum/go-consensus-standards/constants"
+	"github.com/pergamenum/go-consensus-standards/reflection"
+)
+
+func ExampleRef() {
+
+	type Event struct {
+		Created time.Time `json:"created"`
+		Updated time.Time `json:"updated"`
+		Name    string    `json:"name"`
+	}
+
+	fields := reflection.MapTagToFieldType("json", Event{})
+
+	q := Query{Key: "updated", Operator: "GT", Value: "@created"}
+	err := q.ValidateFields(fields, constants.ValidOperators)
+	fmt.Printf("%T %v %v\n", q.Value, q.Value, err)
+
+	q = Query{Key: "updated", Operator: "GT", Value: "@name"}
+	fmt.Println(q.ValidateFields(fields, constants.ValidOperators))
+
+	// Output:
+	// types.Ref @created <nil>
+	// (invalid query: (reference '@name' of type 'string' is not comparable with 'Time'))
+}
+
+func Test_Relative_Times(t *testing.T) {
+
+	// A Wednesday.
+	now := time.Date(2022, 6, 15, 13, 30, 0, 0, time.UTC)
+	defer func(clock func() time.Time) { Now = clock }(Now)
+	Now = func() time.Time { return now }
+
+	type Event struct {
+		Created time.Time `json:"created"`
+	}
+	fields := reflection.MapTagToFieldType("json", Event{})
+
+	tests := map[string]time.Time{
+		"now":          now,
+		"now-24h":      now.Add(-24 * time.Hour),
+		"now+1h30m":    now.Add(90 * time.Minute),
+		"today":        time.Date(2022, 6, 15, 0, 0, 0, 0, time.UTC),
+		"today-7d":     time.Date(2022, 6, 8, 0, 0, 0, 0, time.UTC),
+		"startOfWeek":  time.Date(2022, 6, 13, 0, 0, 0, 0, time.UTC),
+		"startOfMonth": time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC),
+		"startOfYear":  time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	for value, want := range tests {
+		q := Query{Key: "created", Operator: "GE", Value: value}
+		err := q.ValidateFields(fields, constants.ValidOperators)
+		if err != nil || q.Value != want {
+			fmt.Printf("%s: got %v (%v), want %v\n", value, q.Value, err, want)
+			t.Fail()
+		}
+	}
+
+	for _, value := range []string{"now-1x", "today+d", "yesterday"} {
+		q := Query{Key: "created", Operator: "GE", Value: value}
+		if err := q.ValidateFields(fields, constants.ValidOperators); err == nil {
+			fmt.Printf("%s: expected an error, got %v\n", value, q.Value)
+			t.Fail()
+		}
+	}
+}